@@ -0,0 +1,80 @@
+package verbose
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// assignmentRegex matches shell and env file assignments like FOO_TOKEN=abc and export AWS_SECRET_ACCESS_KEY=...
+	assignmentRegex = regexp.MustCompile(`(?:^|[\s;&|(,{])(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)=("(?:[^"\\]|\\.)*"|'[^']*'|[^\s,;&|)}#"']+)`)
+	// flagRegex matches command line flags like --password=... and --api-token ...
+	flagRegex = regexp.MustCompile(`(?:^|\s)--([A-Za-z][A-Za-z0-9_-]*)(?:=|\s+)("(?:[^"\\]|\\.)*"|'[^']*'|[^\s"'-][^\s"']*)`)
+	// headerRegex matches key: value pairs anywhere in a line, like the HTTP headers X-Api-Key: abc and
+	// Authorization: Bearer abc or DB_PASSWORD: hunter2 in a log line, whose value ends at the first whitespace after
+	// its scheme
+	headerRegex = regexp.MustCompile(`(?m)(?:^|[\s;,{(\[])["']?([A-Za-z_][A-Za-z0-9_.-]*)["']?[ \t]*:[ \t]+((?i:(?:bearer|basic|token|digest|bot|negotiate)[ \t]+)?(?:"(?:[^"\\]|\\.)*"|'[^']*'|[^\s,;"']+))`)
+	// envKeyRegex matches keys written like environment variables, which IsSecretEnv classifies
+	envKeyRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	// authSchemeRegex matches the scheme of an Authorization value, which is kept readable
+	authSchemeRegex = regexp.MustCompile(`^(?i:bearer|basic|token|digest|bot|negotiate)\s+`)
+)
+
+// isSecretKey returns true if the key of an assignment, flag or header classifies as secret: with IsSecretEnv when it
+// is written like an environment variable, and with IsSensitiveKey otherwise so that words like height or --root
+// are not mistaken for secrets
+func isSecretKey(key string) bool {
+	if envKeyRegex.MatchString(key) {
+		return IsSecretEnv(key)
+	}
+	return IsSensitiveKey(key)
+}
+
+// findSecretAssignments returns the values of every assignment and flag in input whose key classifies as secret,
+// even when the value was never registered with AddSecret
+func findSecretAssignments(input string) []cleanerMatch {
+	matches := findSecretValues(input, assignmentRegex, "assignment")
+	return append(matches, findSecretValues(input, flagRegex, "flag")...)
+}
+
+// findSecretHeaders returns the values of every header and other key: value pair in input whose key classifies as
+// secret. Cookies are cleaned to the end of the line since every pair of the list may be a session.
+func findSecretHeaders(input string) []cleanerMatch {
+	matches := findSecretValues(input, headerRegex, "header")
+	for i, m := range matches {
+		if line := input[:m.start]; strings.HasSuffix(strings.TrimRight(strings.ToLower(line), ": \t"), "cookie") {
+			end := strings.IndexByte(input[m.end:], '\n')
+			if end == -1 {
+				end = len(input) - m.end
+			}
+			matches[i].end = m.end + len(strings.TrimRight(input[m.end:m.end+end], " \t\r"))
+		}
+	}
+	return matches
+}
+
+// findSecretValues returns the values matched by re, whose first submatch is the key and second is the value,
+// when the key classifies as secret
+func findSecretValues(input string, re *regexp.Regexp, rule string) []cleanerMatch {
+	var matches []cleanerMatch
+	for _, loc := range re.FindAllStringSubmatchIndex(input, -1) {
+		if !isSecretKey(input[loc[2]:loc[3]]) {
+			continue
+		}
+		start, end := loc[4], loc[5]
+		if isBoolOrNull(input[start:end]) {
+			continue
+		}
+		if quote := input[start]; (quote == '"' || quote == '\'') && end-start >= 2 {
+			start, end = start+1, end-1 // keep the quotes of quoted values
+		}
+		if scheme := authSchemeRegex.FindString(input[start:end]); len(scheme) > 0 {
+			start += len(scheme)
+		}
+		end = start + len(strings.TrimRight(input[start:end], " \t"))
+		if end > start {
+			matches = append(matches, cleanerMatch{start: start, end: end, rule: rule})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,119 @@
+package verbose
+
+import "testing"
+
+func TestScrubSecretAssignments(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "env assignment",
+			input: "FOO_TOKEN=abc123 BAR=visible",
+			want:  "FOO_TOKEN=[CLEANED] BAR=visible",
+		},
+		{
+			name:  "exported assignment",
+			input: "export AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI/K7MDENG",
+			want:  "export AWS_SECRET_ACCESS_KEY=[CLEANED]",
+		},
+		{
+			name:  "quoted assignment",
+			input: `DB_PASSWORD="correct horse battery staple"`,
+			want:  `DB_PASSWORD="[CLEANED]"`,
+		},
+		{
+			name:  "flag with equals",
+			input: "mysql --user=app --password=hunter2 shop",
+			want:  "mysql --user=app --password=[CLEANED] shop",
+		},
+		{
+			name:  "flag with a separate value",
+			input: "deploy --api-token 0123abcd --verbose",
+			want:  "deploy --api-token [CLEANED] --verbose",
+		},
+		{
+			name:  "header",
+			input: "X-Api-Key: abcdef0123",
+			want:  "X-Api-Key: [CLEANED]",
+		},
+		{
+			name:  "authorization header keeps the scheme",
+			input: "Authorization: Bearer abc.def.ghi",
+			want:  "Authorization: Bearer [CLEANED]",
+		},
+		{
+			name:  "header value ends at whitespace",
+			input: "X-Auth-Token: abc123 sent to upstream",
+			want:  "X-Auth-Token: [CLEANED] sent to upstream",
+		},
+		{
+			name:  "cookie header is cleaned to the end of the line",
+			input: "Cookie: theme=dark; session=abc123\nnext",
+			want:  "Cookie: [CLEANED]\nnext",
+		},
+		{
+			name:  "prose with a height is kept",
+			input: "height: 10",
+			want:  "height: 10",
+		},
+		{
+			name:  "prose with keys is kept",
+			input: "keys: a,b",
+			want:  "keys: a,b",
+		},
+		{
+			name:  "header in the middle of a line",
+			input: "note that X-Api-Key: abc is a header",
+			want:  "note that X-Api-Key: [CLEANED] is a header",
+		},
+		{
+			name:  "env key with a colon",
+			input: "DB_PASSWORD: hunter2",
+			want:  "DB_PASSWORD: [CLEANED]",
+		},
+		{
+			name:  "lower case key with a colon",
+			input: "token: abc",
+			want:  "token: [CLEANED]",
+		},
+		{
+			name:  "key with a colon before another line",
+			input: "password: hunter2\nnext",
+			want:  "password: [CLEANED]\nnext",
+		},
+		{
+			name:  "key with a colon inside a log line",
+			input: "2024-01-01 config DB_PASSWORD: hunter2 loaded",
+			want:  "2024-01-01 config DB_PASSWORD: [CLEANED] loaded",
+		},
+		{
+			name:  "times and urls are kept",
+			input: "at 10:30: fetched https://example.com/a:b",
+			want:  "at 10:30: fetched https://example.com/a:b",
+		},
+		{
+			name:  "flags that only contain a secret word are kept",
+			input: "serve --root /srv --keyboard us",
+			want:  "serve --root /srv --keyboard us",
+		},
+		{
+			name:  "flags that end with a secret word are kept",
+			input: "sort --sort-key name --pin-version 3",
+			want:  "sort --sort-key name --pin-version 3",
+		},
+		{
+			name:  "keys that are not secret are kept",
+			input: "HOME=/root --name=web Content-Type: text/plain",
+			want:  "HOME=/root --name=web Content-Type: text/plain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if output := Scrub(tt.input); output != tt.want {
+				t.Errorf("Scrub(%q) = %q; want %q", tt.input, output, tt.want)
+			}
+		})
+	}
+}
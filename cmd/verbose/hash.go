@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+// hash writes a manifest of the secrets read from stdin, files and environment variables. Only the SHA512
+// checksums and lengths of the secrets are written, the secrets themselves never are.
+func hash(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("hash", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "usage: verbose hash [flags] [< secrets, one per line] > manifest.json\n\n")
+		fs.PrintDefaults()
+	}
+	var files, envs listFlag
+	fs.Var(&files, "file", "hash the content of a file, labeled with its name, may be repeated")
+	fs.Var(&envs, "env", "hash the value of an environment variable, labeled with its name, may be repeated")
+	label := fs.String("label", "stdin", "label of the secrets read from stdin, numbered when there are several")
+	replacement := fs.String("replacement", "", "replacement of every secret, {label} is replaced with its label")
+	useStdin := fs.Bool("stdin", false, "read secrets from stdin as well as from -file and -env")
+	format := fs.String("format", "manifest", "manifest for labels and replacements, or import for the plain "+
+		"{\"<hash>\": length} form of ImportSecrets")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	if *format != "manifest" && *format != "import" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	var manifest verbose.Manifest
+	add := func(label string, secret []byte) error {
+		entry, err := verbose.NewManifestEntry(label, secret, strings.ReplaceAll(*replacement, "{label}", label))
+		if err != nil {
+			return err
+		}
+		manifest.Secrets = append(manifest.Secrets, entry)
+		return nil
+	}
+	if *useStdin || len(files)+len(envs) == 0 {
+		var lines [][]byte
+		scanner := bufio.NewScanner(stdin)
+		scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+		for scanner.Scan() {
+			if line := strings.TrimSuffix(scanner.Text(), "\r"); len(line) > 0 {
+				lines = append(lines, []byte(line))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		for i, line := range lines {
+			name := *label
+			if len(lines) > 1 {
+				name = fmt.Sprintf("%s-%d", *label, i+1)
+			}
+			if err := add(name, line); err != nil {
+				return err
+			}
+		}
+	}
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := add(filepath.Base(path), []byte(strings.TrimRight(string(content), "\r\n"))); err != nil {
+			return err
+		}
+	}
+	for _, name := range envs {
+		value, found := os.LookupEnv(name)
+		if !found {
+			return fmt.Errorf("environment variable %s is not set", name)
+		}
+		if err := add(name, []byte(value)); err != nil {
+			return err
+		}
+	}
+	if len(manifest.Secrets) == 0 {
+		return errors.New("no secrets to hash")
+	}
+	var output any = manifest
+	if *format == "import" {
+		hashes := make(map[string]int, len(manifest.Secrets))
+		for _, entry := range manifest.Secrets {
+			hashes[entry.Hash] = entry.Length
+		}
+		output = hashes
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+func TestHash(t *testing.T) {
+	t.Setenv("HASH_TEST_PASSWORD", "env-hashed-password")
+	file := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(file, []byte("file-hashed-password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	args := []string{"hash", "-stdin", "-file", file, "-env", "HASH_TEST_PASSWORD", "-replacement", "[{label}]"}
+	if code := run(args, strings.NewReader("stdin-hashed-one\nstdin-hashed-two\n"), &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr.String())
+	}
+	for _, plaintext := range []string{"env-hashed-password", "file-hashed-password", "stdin-hashed"} {
+		if strings.Contains(stdout.String()+stderr.String(), plaintext) {
+			t.Errorf("hash echoed the plaintext %q", plaintext)
+		}
+	}
+	var manifest verbose.Manifest
+	if err := json.Unmarshal([]byte(stdout.String()), &manifest); err != nil {
+		t.Fatalf("hash wrote invalid JSON: %v", err)
+	}
+	var labels []string
+	for _, entry := range manifest.Secrets {
+		labels = append(labels, entry.Label+"="+entry.Replacement)
+	}
+	want := "stdin-1=[stdin-1],stdin-2=[stdin-2],db_password=[db_password],HASH_TEST_PASSWORD=[HASH_TEST_PASSWORD]"
+	if strings.Join(labels, ",") != want {
+		t.Errorf("hash labels = %v; want %s", labels, want)
+	}
+
+	if _, err := verbose.LoadManifest(strings.NewReader(stdout.String())); err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	defer func() {
+		for _, secret := range []string{"stdin-hashed-one", "stdin-hashed-two", "file-hashed-password", "env-hashed-password"} {
+			_ = verbose.RemoveSecret(verbose.SecretBytes(secret))
+		}
+	}()
+	if output := verbose.Redact("pw file-hashed-password"); output != "pw [db_password]" {
+		t.Errorf("Redact() with the hashed manifest = %q", output)
+	}
+}
+
+func TestHashImportFormat(t *testing.T) {
+	var stdout, stderr strings.Builder
+	if code := run([]string{"hash", "-format", "import"}, strings.NewReader("import-form-secret\n"), &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr.String())
+	}
+	var hashes map[string]int
+	if err := json.Unmarshal([]byte(stdout.String()), &hashes); err != nil || len(hashes) != 1 {
+		t.Fatalf("hash -format import wrote %s, %v", stdout.String(), err)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code := run([]string{"hash"}, strings.NewReader("abc\n"), &stdout, &stderr)
+	if code != 1 || strings.Contains(stderr.String(), "abc") {
+		t.Errorf("hash of a short secret = %d, stderr: %s; want 1 without the secret", code, stderr.String())
+	}
+}
@@ -0,0 +1,127 @@
+// Command verbose filters logs through the same scrubbing engine that the verbose package gives Go services, so
+// that the output of make, docker build and friends can be piped through it before it is stored.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+const usage = `usage: verbose <command> [flags]
+
+commands:
+  scrub        remove secrets from stdin and write the result to stdout
+  hash         write a manifest of secrets that holds their checksums, never the secrets
+  run          run a command with its output redacted, like "verbose run -- make deploy"
+  redact-file  rewrite existing log files, gzip compressed or not, without their secrets
+  scan         report where secrets leaked into files and fail when any did
+
+Run "verbose <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command named by args[0] and returns the exit code of the process
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "scrub":
+		err = scrub(args[1:], stdin, stdout, stderr)
+	case "hash":
+		err = hash(args[1:], stdin, stdout, stderr)
+	case "run":
+		err = runCommand(args[1:], stdin, stdout, stderr)
+	case "redact-file":
+		err = redactFile(args[1:], stdout, stderr)
+	case "scan":
+		err = scan(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		_, _ = fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		_, _ = fmt.Fprintf(stderr, "verbose: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	var exit exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 2
+	case errors.As(err, &exit):
+		if exit.err != nil {
+			_, _ = fmt.Fprintf(stderr, "verbose %s: %v\n", args[0], exit.err)
+		}
+		return exit.code
+	default:
+		_, _ = fmt.Fprintf(stderr, "verbose %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+// exitError ends the verbose process with code, like the exit code of a child, printing err when it is set
+type exitError struct {
+	code int
+	err  error
+}
+
+// Error returns the error or the exit code
+func (e exitError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// listFlag collects the values of a flag that may be repeated
+type listFlag []string
+
+// String returns the values joined by commas
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set appends value
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// secretFlags are the flags that register secrets, shared by the commands that redact
+type secretFlags struct {
+	manifests listFlag
+	env       bool
+}
+
+// register adds the secret flags to fs
+func (sf *secretFlags) register(fs *flag.FlagSet) {
+	fs.Var(&sf.manifests, "manifest", "register the secrets of a manifest file, may be repeated")
+	fs.BoolVar(&sf.env, "env", sf.env, "register the values of environment variables whose names look secret")
+}
+
+// load registers the secrets the flags ask for
+func (sf *secretFlags) load() error {
+	for _, path := range sf.manifests {
+		if _, err := verbose.LoadManifestFile(path); err != nil {
+			return fmt.Errorf("manifest %s: %w", path, err)
+		}
+	}
+	if sf.env {
+		if _, err := verbose.HarvestEnv(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
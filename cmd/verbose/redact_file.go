@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+// redactFile rewrites log files without the secrets they leaked and reports the replacements of each
+func redactFile(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("redact-file", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "usage: verbose redact-file [flags] file...\n\n")
+		fs.PrintDefaults()
+	}
+	var opts verbose.RedactFileOptions
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "report the replacements without rewriting the files")
+	fs.BoolVar(&opts.Rinse, "rinse", false, "render terminal sequences too")
+	fs.StringVar(&opts.Backup, "backup", "", "keep the original files with this suffix, like .bak")
+	var sf secretFlags
+	sf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := sf.load(); err != nil {
+		return err
+	}
+	var errs []error
+	for _, path := range fs.Args() {
+		count, err := verbose.RedactFile(path, opts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(stdout, "%s: %d replacements\n", path, count)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactFile(t *testing.T) {
+	dir := t.TempDir()
+	leaked, clean := filepath.Join(dir, "leaked.log"), filepath.Join(dir, "clean.log")
+	if err := os.WriteFile(leaked, []byte("export API_TOKEN=abc123\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(clean, []byte("nothing to see\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	if code := run([]string{"redact-file", leaked, clean}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr.String())
+	}
+	want := leaked + ": 1 replacements\n" + clean + ": 0 replacements\n"
+	if stdout.String() != want {
+		t.Errorf("run() wrote %q; want %q", stdout.String(), want)
+	}
+	if data, _ := os.ReadFile(leaked); string(data) != "export API_TOKEN=[CLEANED]\n" {
+		t.Errorf("redact-file left %q", data)
+	}
+	if code := run([]string{"redact-file", filepath.Join(dir, "missing.log")}, strings.NewReader(""), &stdout, &stderr); code != 1 {
+		t.Errorf("run() of a missing file = %d; want 1", code)
+	}
+}
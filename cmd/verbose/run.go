@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+// forwardedSignals are passed on to the child of run instead of ending verbose
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// terminalSignals are sent by the terminal to its whole foreground process group, so they are not forwarded to a
+// child that shares the group of verbose
+var terminalSignals = []os.Signal{os.Interrupt, syscall.SIGHUP, syscall.SIGQUIT}
+
+// runCommand starts the command after -- with its stdout and stderr redacted, forwards signals to it and ends with
+// its exit code. A command that reads a terminal stays in the foreground process group of verbose, where it gets the
+// signals of the terminal itself, any other runs in a group of its own so that forwarded signals reach it once. The
+// values of secret environment variables are registered first, since the child inherits them.
+func runCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "usage: verbose run [flags] -- command [args...]\n\n")
+		fs.PrintDefaults()
+	}
+	sf := secretFlags{env: true}
+	sf.register(fs)
+	logDir := fs.String("log-dir", "", "also write the redacted output to a log file in this directory")
+	logName := fs.String("log-name", "", "name of the log file, without its .log extension, defaults to verbose")
+	truncate := fs.Bool("log-truncate", false, "truncate the log file instead of appending to it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := sf.load(); err != nil {
+		return err
+	}
+	if len(*logDir) > 0 || len(*logName) > 0 {
+		logFile, err := verbose.OpenLogFile(verbose.Options{Dir: *logDir, Name: *logName, Truncate: *truncate})
+		if err != nil {
+			return err
+		}
+		defer func() { _ = logFile.Close() }()
+		stdout, stderr = io.MultiWriter(stdout, logFile), io.MultiWriter(stderr, logFile)
+	}
+	outWriter, errWriter := verbose.NewRedactWriter(stdout), verbose.NewRedactWriter(stderr)
+	child := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	child.Stdin, child.Stdout, child.Stderr = stdin, outWriter, errWriter
+	foreground := isTerminal(stdin)
+	if !foreground {
+		setProcessGroup(child)
+	}
+	if err := child.Start(); err != nil {
+		code := 126
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+			code = 127
+		}
+		return exitError{code: code, err: err}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if !foreground || !slices.Contains(terminalSignals, sig) {
+					_ = child.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	err := child.Wait()
+	signal.Stop(signals)
+	close(done)
+	flushErr := errors.Join(outWriter.Close(), errWriter.Close())
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitError{code: exitCode(exitErr)}
+	}
+	if err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// isTerminal returns true if r is a terminal, or another character device, that the child reads directly
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// exitCode returns the exit code of a child that failed, 128 plus the signal number when a signal ended it like
+// shells report it
+func exitCode(err *exec.ExitError) int {
+	if status, ok := err.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return err.ExitCode()
+}
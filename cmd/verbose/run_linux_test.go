@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// openPty returns the master and the terminal of a new pseudo terminal
+func openPty(t *testing.T) (*os.File, *os.File) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo terminals: %v", err)
+	}
+	t.Cleanup(func() { _ = master.Close() })
+	var n, unlock uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Skipf("unlocking the pseudo terminal: %v", errno)
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Skipf("numbering the pseudo terminal: %v", errno)
+	}
+	tty, err := os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("opening the pseudo terminal: %v", err)
+	}
+	t.Cleanup(func() { _ = tty.Close() })
+	return master, tty
+}
+
+func TestRunCommandReadsTerminal(t *testing.T) {
+	if os.Getenv("VERBOSE_TEST_PTY") == "1" {
+		os.Exit(run([]string{"run", "-env=false", "--", "sh", "-c", "echo started; read x; echo got:$x"}, os.Stdin, os.Stdout, os.Stderr))
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	master, tty := openPty(t)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunCommandReadsTerminal$")
+	cmd.Env = append(os.Environ(), "VERBOSE_TEST_PTY=1")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	_ = tty.Close()
+	output := make(chan string)
+	go func() {
+		var read strings.Builder
+		buf := make([]byte, 256)
+		for {
+			n, err := master.Read(buf)
+			read.Write(buf[:n])
+			if strings.Contains(read.String(), "started") && n > 0 && !strings.Contains(read.String(), "hello") {
+				_, _ = master.Write([]byte("hello\n"))
+			}
+			if err != nil || strings.Contains(read.String(), "got:hello") {
+				output <- read.String()
+				return
+			}
+		}
+	}()
+	select {
+	case out := <-output:
+		if !strings.Contains(out, "got:hello") {
+			t.Errorf("run() under a terminal wrote %q; want the line it read", out)
+		}
+	case <-time.After(10 * time.Second):
+		t.Errorf("run() under a terminal did not read its input")
+	}
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// setProcessGroup leaves child in the process group of verbose, signals are not sent to process groups here
+func setProcessGroup(child *exec.Cmd) {}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	t.Setenv("RUN_TEST_API_TOKEN", "run-harvested-token")
+	defer func() { _ = verbose.RemoveSecret(verbose.SecretBytes("run-harvested-token")) }()
+	dir := t.TempDir()
+
+	var stdout, stderr strings.Builder
+	args := []string{"run", "-log-dir", dir, "-log-name", "child", "--",
+		"sh", "-c", `echo "token $RUN_TEST_API_TOKEN"; echo "DB_PASSWORD=hunter2" >&2; exit 3`}
+	if code := run(args, strings.NewReader(""), &stdout, &stderr); code != 3 {
+		t.Errorf("run() = %d; want the exit code 3 of the child, stderr: %s", code, stderr.String())
+	}
+	if stdout.String() != "token "+strings.Repeat("*", 36)+"\n" {
+		t.Errorf("run() stdout = %q", stdout.String())
+	}
+	if stderr.String() != "DB_PASSWORD=[CLEANED]\n" {
+		t.Errorf("run() stderr = %q", stderr.String())
+	}
+	logged, err := os.ReadFile(filepath.Join(dir, "child.log"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(logged), "run-harvested-token") || strings.Contains(string(logged), "hunter2") ||
+		!strings.Contains(string(logged), "DB_PASSWORD=[CLEANED]") {
+		t.Errorf("log file = %q", logged)
+	}
+}
+
+func TestRunCommandSignaled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	var stdout, stderr strings.Builder
+	if code := run([]string{"run", "-env=false", "--", "sh", "-c", "kill -TERM $$"}, strings.NewReader(""), &stdout, &stderr); code != 143 {
+		t.Errorf("run() = %d; want 143 for a child ended by SIGTERM", code)
+	}
+	if code := run([]string{"run", "--", "verbose-test-missing-command"}, strings.NewReader(""), &stdout, &stderr); code != 127 {
+		t.Errorf("run() = %d; want 127 for a missing command", code)
+	}
+}
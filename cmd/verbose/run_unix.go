@@ -0,0 +1,15 @@
+//go:build unix
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts child in a process group of its own, so that the signals sent to the group of verbose, like
+// a CI runner cancelling a job, reach it only once, when runCommand forwards them. It is only called for children that
+// do not read a terminal, since a background group is stopped by SIGTTIN when it does.
+func setProcessGroup(child *exec.Cmd) {
+	child.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
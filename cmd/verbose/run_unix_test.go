@@ -0,0 +1,29 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRunCommandProcessGroup(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("/proc is not mounted")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	var stdout, stderr strings.Builder
+	args := []string{"run", "-env=false", "--", "sh", "-c", `cut -d" " -f5 /proc/$$/stat`}
+	if code := run(args, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr.String())
+	}
+	if group := strings.TrimSpace(stdout.String()); group == strconv.Itoa(syscall.Getpgrp()) {
+		t.Errorf("child process group = %s; want a group of its own", group)
+	}
+}
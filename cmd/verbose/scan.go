@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+// sarifSchema is the schema of the SARIF 2.1.0 logs scan writes
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// scan reports where secrets leaked into the files under its arguments and fails when any did, so that it can
+// gate a release
+func scan(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "usage: verbose scan [flags] dir|file...\n\n")
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "text", "output format: text, json or sarif")
+	var include, exclude listFlag
+	fs.Var(&include, "include", "only scan files whose names match this pattern, like *.log, may be repeated")
+	fs.Var(&exclude, "exclude", "skip files and directories whose names match this pattern, may be repeated (default .git)")
+	var sf secretFlags
+	sf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if *format != "text" && *format != "json" && *format != "sarif" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if len(exclude) == 0 {
+		exclude = listFlag{".git"}
+	}
+	if err := sf.load(); err != nil {
+		return err
+	}
+	var findings []verbose.Finding
+	var errs []error
+	for _, target := range fs.Args() {
+		info, err := os.Stat(target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		dir, root := target, "."
+		if !info.IsDir() {
+			dir, root = filepath.Dir(target), filepath.Base(target)
+		}
+		targetFindings, err := verbose.Scan(os.DirFS(dir), verbose.ScanOptions{Root: root, Include: include, Exclude: exclude})
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, f := range targetFindings {
+			f.File = path.Join(filepath.ToSlash(dir), f.File)
+			findings = append(findings, f)
+		}
+	}
+	if err := writeFindings(stdout, *format, findings); err != nil {
+		return err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return exitError{code: 2, err: err}
+	}
+	if len(findings) > 0 {
+		return exitError{code: 1}
+	}
+	return nil
+}
+
+// writeFindings writes findings to w in format
+func writeFindings(w io.Writer, format string, findings []verbose.Finding) error {
+	switch format {
+	case "json":
+		if findings == nil {
+			findings = []verbose.Finding{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(findings)
+	case "sarif":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(newSarifLog(findings))
+	default:
+		for _, f := range findings {
+			if _, err := fmt.Fprintf(w, "%s:%d:%d: %s\n", f.File, f.Line, f.Column, f.Rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// sarifLog is the subset of a SARIF 2.1.0 log that code scanning tools need
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// newSarifLog describes findings as the results of a SARIF run, with a rule for every rule that fired
+func newSarifLog(findings []verbose.Finding) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "verbose",
+			Version:        verbose.VERSION,
+			InformationURI: "https://github.com/andreimerlescu/verbose",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               f.Rule,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("Secret matched by %s", f.Rule)},
+			})
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  f.Rule,
+			Level:   "error",
+			Message: sarifMessage{Text: fmt.Sprintf("A secret matched by %s leaked into %s", f.Rule, f.File)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.File},
+				Region:           sarifRegion{StartLine: f.Line, StartColumn: f.Column},
+			}}},
+		})
+	}
+	return sarifLog{Version: "2.1.0", Schema: sarifSchema, Runs: []sarifRun{run}}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	leaked := filepath.Join(dir, "logs", "app.log")
+	if err := os.WriteFile(leaked, []byte("ok\nexport API_TOKEN=abc123\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "clean.log"), []byte("ok\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	if code := run([]string{"scan", dir}, strings.NewReader(""), &stdout, &stderr); code != 1 {
+		t.Errorf("run() = %d; want 1 when secrets leaked, stderr: %s", code, stderr.String())
+	}
+	want := filepath.ToSlash(dir) + "/logs/app.log:2:18: assignment\n"
+	if stdout.String() != want {
+		t.Errorf("run() wrote %q; want %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"scan", "-format", "sarif", leaked}, strings.NewReader(""), &stdout, &stderr); code != 1 {
+		t.Errorf("run() = %d; want 1", code)
+	}
+	var sarif sarifLog
+	if err := json.Unmarshal([]byte(stdout.String()), &sarif); err != nil {
+		t.Fatalf("scan -format sarif wrote invalid JSON: %v", err)
+	}
+	if len(sarif.Runs) != 1 || len(sarif.Runs[0].Results) != 1 || sarif.Runs[0].Results[0].RuleID != "assignment" ||
+		sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.Region.StartLine != 2 {
+		t.Errorf("scan -format sarif = %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"scan", "-format", "json", filepath.Join(dir, "clean.log")}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Errorf("run() of a clean file = %d; want 0", code)
+	}
+	if strings.TrimSpace(stdout.String()) != "[]" {
+		t.Errorf("scan -format json of a clean file = %q; want []", stdout.String())
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "image.bin"), []byte("\x00\x01"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"scan", filepath.Join(dir, "image.bin")}, strings.NewReader(""), &stdout, &stderr); code != 2 {
+		t.Errorf("run() of a skipped file = %d; want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "skipped: binary file") {
+		t.Errorf("scan of a skipped file wrote %q to stderr", stderr.String())
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+// scrub redacts stdin to stdout. Lines are redacted as they arrive unless the whole input is needed, for
+// condensers like --yum that summarize an entire transaction.
+func scrub(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("scrub", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "usage: verbose scrub [flags] < input > output\n\n")
+		fs.PrintDefaults()
+	}
+	docker := fs.Bool("docker", false, "condense docker build output as it streams")
+	yum := fs.Bool("yum", false, "condense yum and dnf output, implies -whole")
+	contentType := fs.String("type", "", "condense output of a registered content type like apt, pip or npm, implies -whole")
+	whole := fs.Bool("whole", false, "read all of stdin before redacting it, which cleans multi-line documents as a whole")
+	var sf secretFlags
+	sf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	modes := 0
+	for _, set := range []bool{*docker, *yum, len(*contentType) > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return errors.New("only one of -docker, -yum and -type may be given")
+	}
+	if err := sf.load(); err != nil {
+		return err
+	}
+	switch {
+	case *yum:
+		*contentType = verbose.ContentYum
+	case *docker && !*whole:
+		rw := verbose.NewRedactWriter(stdout)
+		if err := verbose.TruncateDockerBuildStream(stdin, rw); err != nil {
+			return err
+		}
+		return rw.Close()
+	case *docker:
+		*contentType = verbose.ContentDocker
+	}
+	if len(*contentType) > 0 || *whole {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, verbose.RedactAs(*contentType, string(input)))
+		return err
+	}
+	rw := verbose.NewRedactWriter(stdout)
+	if _, err := io.Copy(rw, stdin); err != nil {
+		return err
+	}
+	return rw.Close()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/verbose"
+)
+
+func TestScrub(t *testing.T) {
+	secret := verbose.SecretBytes("cli-manifest-secret")
+	hash, err := secret.Sha512()
+	if err != nil {
+		t.Fatalf("Sha512() error = %v", err)
+	}
+	defer func() { _ = verbose.RemoveSecret(secret) }()
+	manifest := filepath.Join(t.TempDir(), "secrets.json")
+	if err := os.WriteFile(manifest, []byte(`{"secrets":[{"hash":"`+hash+`","length":19,"replacement":"[CLI]"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+		want  string
+		code  int
+	}{
+		{
+			name:  "stream",
+			args:  []string{"scrub", "-manifest", manifest},
+			input: "using cli-manifest-secret\nexport API_TOKEN=abc123\n\x1b[32mok\x1b[0m\n",
+			want:  "using [CLI]\nexport API_TOKEN=[CLEANED]\nok\n",
+		},
+		{
+			name:  "yum",
+			args:  []string{"scrub", "-yum"},
+			input: "Last metadata expiration check: 0:00:01 ago.\nDependencies resolved.\nNothing to do.\nComplete!\n",
+			want:  verbose.RedactAs(verbose.ContentYum, "Last metadata expiration check: 0:00:01 ago.\nDependencies resolved.\nNothing to do.\nComplete!\n"),
+		},
+		{name: "conflicting modes", args: []string{"scrub", "-docker", "-yum"}, code: 1},
+		{name: "unknown command", args: []string{"rinse"}, code: 2},
+		{name: "no command", code: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := run(tt.args, strings.NewReader(tt.input), &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() = %d; want %d, stderr: %s", code, tt.code, stderr.String())
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("run() wrote %q; want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubDocker(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("..", "..", "docker-build.stdout.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	if code := run([]string{"scrub", "-docker"}, strings.NewReader(string(input)), &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr.String())
+	}
+	if want := verbose.TruncateDockerBuild(string(input)); stdout.String() != want {
+		t.Errorf("run() wrote\n%s\nwant\n%s", stdout.String(), want)
+	}
+}
@@ -0,0 +1,132 @@
+package verbose
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DockerTailLines is how many output lines of a failing docker build step are kept by TruncateDockerBuild
+var DockerTailLines = 20
+
+// dockerStepOutputRegex matches the timestamped output lines of a BuildKit step, like "#8 30.84 Get:1 http://..."
+var dockerStepOutputRegex = regexp.MustCompile(`^#(\d+) \d+\.\d+ `)
+
+// dockerStepErrorRegex matches the line BuildKit prints when a step fails
+var dockerStepErrorRegex = regexp.MustCompile(`^#(\d+) ERROR\b`)
+
+// dockerCondenser keeps the state needed to condense BuildKit output one line at a time
+type dockerCondenser struct {
+	open  map[string]bool     // steps that have started but have not finished yet
+	tails map[string][]string // last DockerTailLines output lines of each running step
+	last  string              // step that most recently printed output
+}
+
+func newDockerCondenser() *dockerCondenser {
+	return &dockerCondenser{
+		open:  make(map[string]bool),
+		tails: make(map[string][]string),
+	}
+}
+
+// line returns the lines to keep for a single line of BuildKit output
+func (dc *dockerCondenser) line(line string) []string {
+	if len(strings.TrimSpace(line)) == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(line, "#") {
+		if dc.open[dc.last] {
+			dc.keepTail(dc.last, line) // a step's output line that was split by a carriage return
+			return nil
+		}
+		return []string{line} // the failure summary BuildKit prints after the steps
+	}
+	if m := dockerStepErrorRegex.FindStringSubmatch(line); m != nil {
+		kept := append(dc.tails[m[1]], line)
+		dc.finish(m[1])
+		return kept
+	}
+	for _, re := range dockerRegexes {
+		if re.MatchString(line) {
+			step := dockerStep(line)
+			if strings.Contains(line, " DONE ") || strings.HasSuffix(line, " CACHED") || strings.Contains(line, " CANCELED") {
+				dc.finish(step)
+			} else {
+				dc.open[step] = true
+			}
+			return []string{line}
+		}
+	}
+	if m := dockerStepOutputRegex.FindStringSubmatch(line); m != nil {
+		dc.keepTail(m[1], line)
+	}
+	return nil
+}
+
+// keepTail remembers line as the latest output of step, dropping anything older than DockerTailLines
+func (dc *dockerCondenser) keepTail(step, line string) {
+	dc.last = step
+	if DockerTailLines <= 0 {
+		return
+	}
+	tail := append(dc.tails[step], line)
+	if len(tail) > DockerTailLines {
+		tail = tail[len(tail)-DockerTailLines:]
+	}
+	dc.tails[step] = tail
+}
+
+// finish forgets the state of a step that is DONE, CACHED, CANCELED or failed
+func (dc *dockerCondenser) finish(step string) {
+	delete(dc.open, step)
+	delete(dc.tails, step)
+}
+
+// dockerStep returns the step number of a BuildKit line like "#8 DONE 79.6s"
+func dockerStep(line string) string {
+	step, _, _ := strings.Cut(strings.TrimPrefix(line, "#"), " ")
+	return step
+}
+
+// TruncateDockerBuild reduces BuildKit output to the step headers, DONE/CACHED/ERROR lines and the tail of any
+// failing step's output
+func TruncateDockerBuild(input string) string {
+	dc := newDockerCondenser()
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(input, "\n"), "\n") {
+		for _, kept := range dc.line(strings.TrimSuffix(line, "\r")) {
+			b.WriteString(kept)
+			b.WriteByte('\n')
+		}
+	}
+	output := b.String()
+	if !strings.HasSuffix(input, "\n") {
+		output = strings.TrimSuffix(output, "\n")
+	}
+	return output
+}
+
+// TruncateDockerBuildStream is TruncateDockerBuild for BuildKit output that is still being written, such as the
+// stdout of a running docker build. Each kept line is written to w as soon as it is known to be kept.
+func TruncateDockerBuildStream(r io.Reader, w io.Writer) error {
+	dc := newDockerCondenser()
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if len(line) > 0 {
+			for _, kept := range dc.line(strings.TrimRight(line, "\r\n")) {
+				if _, err := io.WriteString(w, kept+"\n"); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package verbose
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTruncateDockerBuild(t *testing.T) {
+	fixture, err := os.ReadFile("docker-build.stdout.txt")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	output := TruncateDockerBuild(string(fixture))
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 30 {
+		t.Errorf("TruncateDockerBuild() kept %d lines; want 30\n%s", len(lines), output)
+	}
+	for _, want := range []string{
+		`#0 building with "default" instance using docker driver`,
+		"#5 [2/9] WORKDIR /app\n#5 CACHED\n",
+		"#11 DONE 160.4s\n",
+		"#13 [9/9] RUN chown -R www-data:www-data /var/www/html\n#13 DONE 1.8s\n",
+		"#14 naming to docker.io/library/lamp:latest done\n#14 DONE 3.8s\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("TruncateDockerBuild() is missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Get:1 http://deb.debian.org", "Reading database", "exporting layers"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("TruncateDockerBuild() kept step output %q", unwanted)
+		}
+	}
+
+	var streamed bytes.Buffer
+	if err := TruncateDockerBuildStream(bytes.NewReader(fixture), &streamed); err != nil {
+		t.Fatalf("TruncateDockerBuildStream() error = %v", err)
+	}
+	if streamed.String() != output {
+		t.Errorf("TruncateDockerBuildStream() differs from TruncateDockerBuild()\n%s", streamed.String())
+	}
+}
+
+func TestTruncateDockerBuildFailure(t *testing.T) {
+	previous := DockerTailLines
+	defer func() { DockerTailLines = previous }()
+	DockerTailLines = 2
+
+	input := `#4 [2/3] RUN make
+#4 0.101 compiling a.c
+#4 0.202 compiling b.c
+#4 0.303 b.c:1: error: expected ';'
+#4 ERROR: process "/bin/sh -c make" did not complete successfully: exit code: 2
+------
+ > [2/3] RUN make:
+------
+ERROR: failed to solve: process "/bin/sh -c make" did not complete successfully: exit code: 2
+`
+	want := `#4 [2/3] RUN make
+#4 0.202 compiling b.c
+#4 0.303 b.c:1: error: expected ';'
+#4 ERROR: process "/bin/sh -c make" did not complete successfully: exit code: 2
+------
+ > [2/3] RUN make:
+------
+ERROR: failed to solve: process "/bin/sh -c make" did not complete successfully: exit code: 2
+`
+	if output := TruncateDockerBuild(input); output != want {
+		t.Errorf("TruncateDockerBuild() = %q; want %q", output, want)
+	}
+}
@@ -0,0 +1,156 @@
+package verbose
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// EntropyOptions configures the high-entropy token detector that Scrub runs when Enabled
+type EntropyOptions struct {
+	Enabled         bool    // Enabled turns the detector on, it is off by default
+	MinLength       int     // MinLength is the shortest base62 or base64 token considered
+	HexMinLength    int     // HexMinLength is the shortest hex token considered
+	HexThreshold    float64 // HexThreshold is the Shannon entropy in bits per character a hex token needs
+	Base62Threshold float64 // Base62Threshold is the Shannon entropy in bits per character a base62 token needs
+	Base64Threshold float64 // Base64Threshold is the Shannon entropy in bits per character a base64 token needs
+}
+
+// EntropyDetection configures the high-entropy token detector, which redacts likely credentials that were never
+// registered with AddSecret and do not match any KeyType
+var EntropyDetection = EntropyOptions{
+	Enabled:         false,
+	MinLength:       20,
+	HexMinLength:    32,
+	HexThreshold:    3.0,
+	Base62Threshold: 3.8,
+	Base64Threshold: 4.2,
+}
+
+// entropyRule labels the matches of the high-entropy token detector
+const entropyRule = "high-entropy"
+
+// entropyTokenRegex splits input into candidate tokens made of base64, base64url and hex characters
+var entropyTokenRegex = regexp.MustCompile(`[A-Za-z0-9+/_-]+=*`)
+
+// entropyAllowlist holds the patterns of known-safe tokens, such as commit SHAs and UUIDs
+var entropyAllowlist = []*regexp.Regexp{
+	regexp.MustCompile(`^[0-9a-f]{40}$`), // git commit SHAs
+	regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`), // UUIDs
+}
+
+// entropyDigestRegex matches the hex of sha256: digests, like the image digests of docker and OCI registries, which
+// are allowed only after their sha256: prefix since a bare 64 character hex token may as well be a 256-bit key
+var entropyDigestRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// entropyAllowed holds the exact tokens that are known to be safe
+var entropyAllowed = make(map[string]struct{})
+
+// emu guards entropyAllowlist and entropyAllowed
+var emu = &sync.RWMutex{}
+
+// AllowEntropyToken stops the high-entropy token detector from redacting the exact token
+func AllowEntropyToken(token string) {
+	emu.Lock()
+	entropyAllowed[token] = struct{}{}
+	emu.Unlock()
+}
+
+// AllowEntropyPattern stops the high-entropy token detector from redacting tokens matched entirely by re
+func AllowEntropyPattern(re *regexp.Regexp) {
+	if re == nil {
+		return
+	}
+	emu.Lock()
+	entropyAllowlist = append(entropyAllowlist, re)
+	emu.Unlock()
+}
+
+// isEntropyAllowed returns true if the token is on the allowlist
+func isEntropyAllowed(token string) bool {
+	emu.RLock()
+	defer emu.RUnlock()
+	if _, ok := entropyAllowed[token]; ok {
+		return true
+	}
+	for _, re := range entropyAllowlist {
+		if re.MatchString(token) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShannonEntropy returns the Shannon entropy of s in bits per character
+func ShannonEntropy(s string) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy, length := 0.0, float64(len(s))
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / length
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// isHighEntropyToken scores the token against the thresholds of its charset
+func isHighEntropyToken(token string, opts EntropyOptions) bool {
+	var lower, upper, digit, other bool
+	hex := true
+	for i := 0; i < len(token); i++ {
+		switch c := token[i]; {
+		case c >= '0' && c <= '9':
+			digit = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+			hex = hex && c <= 'f'
+		case c >= 'A' && c <= 'Z':
+			upper = true
+			hex = hex && c <= 'F'
+		default:
+			other, hex = true, false
+		}
+	}
+	switch {
+	case hex:
+		return len(token) >= opts.HexMinLength && digit && (lower || upper) &&
+			ShannonEntropy(token) >= opts.HexThreshold
+	case len(token) < opts.MinLength || !digit || !(lower || upper):
+		return false
+	case !other:
+		return ShannonEntropy(token) >= opts.Base62Threshold
+	default:
+		// paths and dashed identifiers are base64 characters too, so mixed case is required as well
+		return lower && upper && ShannonEntropy(token) >= opts.Base64Threshold
+	}
+}
+
+// findHighEntropyTokens returns every token of input that scores as a likely credential
+func findHighEntropyTokens(input string) []cleanerMatch {
+	opts := EntropyDetection
+	if !opts.Enabled {
+		return nil
+	}
+	var matches []cleanerMatch
+	for _, loc := range entropyTokenRegex.FindAllStringIndex(input, -1) {
+		if strings.HasSuffix(input[:loc[0]], prefix) && isEscrowBlob(input[loc[0]:loc[1]]) {
+			continue // the escrow blobs of Escrow are ciphertext already
+		}
+		token := strings.TrimRight(input[loc[0]:loc[1]], "=")
+		if strings.HasSuffix(input[:loc[0]], "sha256:") && entropyDigestRegex.MatchString(token) {
+			continue
+		}
+		if isHighEntropyToken(token, opts) && !isEntropyAllowed(input[loc[0]:loc[1]]) {
+			matches = append(matches, cleanerMatch{start: loc[0], end: loc[1], rule: entropyRule})
+		}
+	}
+	return matches
+}
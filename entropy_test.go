@@ -0,0 +1,103 @@
+package verbose
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	tests := map[string]float64{
+		"":         0,
+		"aaaa":     0,
+		"abab":     1,
+		"abcdefgh": 3,
+	}
+	for input, want := range tests {
+		if got := ShannonEntropy(input); got != want {
+			t.Errorf("ShannonEntropy(%q) = %v; want %v", input, got, want)
+		}
+	}
+}
+
+func TestHighEntropyDetection(t *testing.T) {
+	previous := EntropyDetection
+	defer func() { EntropyDetection = previous }()
+	emu.RLock()
+	allowlist, allowed := slices.Clone(entropyAllowlist), maps.Clone(entropyAllowed)
+	emu.RUnlock()
+	t.Cleanup(func() {
+		emu.Lock()
+		entropyAllowlist, entropyAllowed = allowlist, allowed
+		emu.Unlock()
+	})
+
+	input := "aws secret wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY here"
+	if output := Scrub(input); output != input {
+		t.Errorf("Scrub() = %q; the detector should be off by default", output)
+	}
+	EntropyDetection.Enabled = true
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "base64 secret",
+			input: input,
+			want:  "aws secret [CLEANED] here",
+		},
+		{
+			name:  "base62 token",
+			input: "key=Zx8Qp2Lm9Vr4Tn7Ws1Yb6Kd3 ok",
+			want:  "key=[CLEANED] ok",
+		},
+		{
+			name:  "hex secret",
+			input: "secret 8f3a9c1d7e2b4a6f0c5d9e8b7a1f2c3d4e5f6a7b8c9d0e1f",
+			want:  "secret [CLEANED]",
+		},
+		{
+			name:  "commit sha is allowed",
+			input: "commit 9fceb02d0ae598e95dc970b74767f19372d61af8",
+			want:  "commit 9fceb02d0ae598e95dc970b74767f19372d61af8",
+		},
+		{
+			name:  "uuid is allowed",
+			input: "request 3f2b8c1e-9d4a-4e6b-a7c2-1b5d8e9f0a3c",
+			want:  "request 3f2b8c1e-9d4a-4e6b-a7c2-1b5d8e9f0a3c",
+		},
+		{
+			name:  "image digest is allowed",
+			input: "pulled app@sha256:3b1f0c9e8d7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c",
+			want:  "pulled app@sha256:3b1f0c9e8d7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c",
+		},
+		{
+			name:  "bare 256-bit hex key",
+			input: "key 3b1f0c9e8d7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c",
+			want:  "key [CLEANED]",
+		},
+		{
+			name:  "words and paths are kept",
+			input: "installing /usr/local/lib/php/extensions/no-debug-non-zts-20220829 internationalization",
+			want:  "installing /usr/local/lib/php/extensions/no-debug-non-zts-20220829 internationalization",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if output := Scrub(tt.input); output != tt.want {
+				t.Errorf("Scrub(%q) = %q; want %q", tt.input, output, tt.want)
+			}
+		})
+	}
+
+	AllowEntropyToken("Zx8Qp2Lm9Vr4Tn7Ws1Yb6Kd3")
+	AllowEntropyPattern(regexp.MustCompile(`^build-[0-9A-Za-z]+$`))
+	for _, allowed := range []string{"ref Zx8Qp2Lm9Vr4Tn7Ws1Yb6Kd3", "id build-Q8z1Xc4Vb7Nm2Lk5Jh9G"} {
+		if output := Scrub(allowed); output != allowed {
+			t.Errorf("Scrub(%q) = %q; want the allowlisted token kept", allowed, output)
+		}
+	}
+}
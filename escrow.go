@@ -0,0 +1,84 @@
+package verbose
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// escrowBlobRegex matches the ENC: blobs of SecureBytes.EncryptUsingKey
+var escrowBlobRegex = regexp.MustCompile(`ENC:[A-Za-z0-9+/]+={0,2}`)
+
+// escrowOverhead is the AES-GCM nonce and tag that every escrow blob holds besides the secret
+const escrowOverhead = 12 + 16
+
+// isEscrowBlob returns true if token, the text after an ENC: prefix, is shaped like an escrow blob: padded standard
+// base64 of a GCM nonce, a sealed secret and a tag. The blob cannot be authenticated without the escrow key, so this
+// only keeps text that merely follows ENC: from passing as ciphertext.
+func isEscrowBlob(token string) bool {
+	ciphertext, err := base64.StdEncoding.Strict().DecodeString(token)
+	return err == nil && len(ciphertext) > escrowOverhead
+}
+
+// Escrow replaces every secret inline with an AES-GCM ENC: blob encrypted with SecureBytes.EncryptUsingKey under
+// key, an escrow key kept apart from the logs. The log never holds the plaintext, but a holder of the key can
+// restore it with RevealLine. The key must be 16, 24 or 32 bytes long. A secret that cannot be encrypted is
+// replaced with the [CLEANED] placeholder.
+func Escrow(key SecureBytes) (RedactionStyle, error) {
+	if l := len(key); l != 16 && l != 24 && l != 32 {
+		return nil, fmt.Errorf("invalid escrow key length: expected 16, 24, or 32, got %d", l)
+	}
+	key = append(SecureBytes(nil), key...)
+	return RedactionFunc(func(secret string) string {
+		sb := SecureBytes(secret)
+		blob, err := sb.EncryptUsingKey(key)
+		if err != nil {
+			return cleanedPlaceholder
+		}
+		return blob
+	}), nil
+}
+
+// RevealLine restores the secrets that Escrow replaced in line with the escrow key. Blobs that do not decrypt under
+// key are left in place and reported in the returned error.
+func RevealLine(line string, key SecureBytes) (string, error) {
+	var errs []error
+	var b strings.Builder
+	last := 0
+	for _, loc := range escrowBlobRegex.FindAllStringIndex(line, -1) {
+		plaintext, end, err := revealBlob(line[loc[0]:loc[1]], key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("blob at %d: %w", loc[0], err))
+			continue
+		}
+		b.WriteString(line[last:loc[0]])
+		b.WriteString(plaintext)
+		last = loc[0] + end
+	}
+	b.WriteString(line[last:])
+	return b.String(), errors.Join(errs...)
+}
+
+// revealBlob decrypts the blob at the start of match and returns the plaintext with the length of the blob. A blob
+// without padding may run into the base64 characters of the text after it, so shorter candidates are tried until
+// one authenticates.
+func revealBlob(match string, key SecureBytes) (string, int, error) {
+	end := len(prefix) + (len(match)-len(prefix))/4*4
+	var err error
+	for ; end > len(prefix); end -= 4 {
+		sb := SecureBytes(match[:end])
+		var plaintext string
+		if plaintext, err = sb.DecryptUsingKey(key); err == nil {
+			return plaintext, end, nil
+		}
+		if strings.HasSuffix(match[:end], "=") {
+			break // padding only ends a blob
+		}
+	}
+	if err == nil {
+		err = errors.New("blob is too short")
+	}
+	return "", 0, err
+}
@@ -0,0 +1,72 @@
+package verbose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscrow(t *testing.T) {
+	key := SecureBytes("0123456789abcdef0123456789abcdef")
+	if _, err := Escrow(SecureBytes("short")); err == nil {
+		t.Errorf("Escrow() with a short key should fail")
+	}
+	style, err := Escrow(key)
+	if err != nil {
+		t.Fatalf("Escrow() error = %v", err)
+	}
+	secret := "escrowed-db-password"
+	if err := AddSecretWithStyle(SecretBytes(secret), style); err != nil {
+		t.Fatalf("AddSecretWithStyle() error = %v", err)
+	}
+	defer func() { _ = RemoveSecret(SecretBytes(secret)) }()
+
+	line := "connecting with " + secret + "xyz and DB_TOKEN=tok-12345\n"
+	SetRuleStyle("assignment", style)
+	defer SetRuleStyle("assignment", nil)
+	redacted := Redact(line)
+	if strings.Contains(redacted, secret) || strings.Contains(redacted, "tok-12345") {
+		t.Fatalf("Redact() leaked plaintext: %q", redacted)
+	}
+	if strings.Count(redacted, prefix) != 2 {
+		t.Errorf("Redact() = %q; want two %s blobs", redacted, prefix)
+	}
+
+	revealed, err := RevealLine(redacted, key)
+	if err != nil {
+		t.Fatalf("RevealLine() error = %v", err)
+	}
+	if revealed != line {
+		t.Errorf("RevealLine() = %q; want %q", revealed, line)
+	}
+
+	wrong, err := RevealLine(redacted, SecureBytes("fedcba9876543210fedcba9876543210"))
+	if err == nil {
+		t.Errorf("RevealLine() with the wrong key should fail")
+	}
+	if wrong != redacted {
+		t.Errorf("RevealLine() with the wrong key = %q; want the line unchanged", wrong)
+	}
+}
+
+func TestEscrowBlobsAreNotHighEntropy(t *testing.T) {
+	defer func(opts EntropyOptions) { EntropyDetection = opts }(EntropyDetection)
+	EntropyDetection.Enabled = true
+	sb := SecureBytes("an escrowed value")
+	blob, err := sb.EncryptUsingKey(SecureBytes("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("EncryptUsingKey() error = %v", err)
+	}
+	if output := Scrub("value " + blob); output != "value "+blob {
+		t.Errorf("Scrub() = %q; want the escrow blob kept", output)
+	}
+}
+
+func TestEscrowPrefixDoesNotHideSecrets(t *testing.T) {
+	defer func(opts EntropyOptions) { EntropyDetection = opts }(EntropyDetection)
+	EntropyDetection.Enabled = true
+	for _, token := range []string{"q8Zr3Xv9LmT2pW7kB4nY6cH1sD5fG0jAxK", "q8Zr3Xv9LmT2pW7kB4nY6cH1sD5fG0jA"} {
+		if output := Scrub("value ENC:" + token); strings.Contains(output, token) {
+			t.Errorf("Scrub() = %q; want a token that is not an escrow blob cleaned after ENC:", output)
+		}
+	}
+}
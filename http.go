@@ -0,0 +1,146 @@
+package verbose
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// SensitiveHeaders lists the HTTP headers whose values DumpRequest and DumpResponse always mask. Any other header
+// whose name classifies as secret with IsSecretEnv, like X-Api-Key, is masked as well.
+var SensitiveHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token",
+	"X-Amz-Security-Token",
+}
+
+// MaxDumpBodySize caps the number of body bytes DumpRequest and DumpResponse include in the dump
+var MaxDumpBodySize int64 = 64 << 10
+
+// DumpRequest is httputil.DumpRequest with the SensitiveHeaders masked and the body, when asked for, capped at
+// MaxDumpBodySize and sanitized with Redact. The body of req is restored so that it can still be sent or read.
+func DumpRequest(req *http.Request, body bool) ([]byte, error) {
+	clone := req.Clone(req.Context())
+	clone.Header = maskHeaders(req.Header)
+	clone.Body = nil
+	dump, err := httputil.DumpRequest(clone, false)
+	if err != nil {
+		return nil, err
+	}
+	if !body || req.Body == nil || req.Body == http.NoBody {
+		return []byte(redactHead(dump)), nil
+	}
+	head, restored, err := peekBody(req.Body)
+	req.Body = restored
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(redactHead(dump)), dumpBody(head, req.ContentLength)...), nil
+}
+
+// DumpResponse is httputil.DumpResponse with the SensitiveHeaders masked and the body, when asked for, capped at
+// MaxDumpBodySize and sanitized with Redact. The body of resp is restored so that it can still be read.
+func DumpResponse(resp *http.Response, body bool) ([]byte, error) {
+	clone := *resp
+	clone.Header = maskHeaders(resp.Header)
+	dump, err := httputil.DumpResponse(&clone, false)
+	if err != nil {
+		return nil, err
+	}
+	if !body || resp.Body == nil || resp.Body == http.NoBody {
+		return []byte(redactHead(dump)), nil
+	}
+	head, restored, err := peekBody(resp.Body)
+	resp.Body = restored
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(redactHead(dump)), dumpBody(head, resp.ContentLength)...), nil
+}
+
+// peekBody reads up to MaxDumpBodySize+1 bytes of body and returns them with a body that still yields every byte
+func peekBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	head, err := io.ReadAll(io.LimitReader(body, MaxDumpBodySize+1))
+	restored := struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), body), body}
+	if err != nil {
+		return nil, restored, fmt.Errorf("failed to read body: %w", err)
+	}
+	return head, restored, nil
+}
+
+// dumpBody sanitizes the head of a body, marking it as truncated when it is longer than MaxDumpBodySize. The head is
+// cut with truncateForRedaction so that a secret or a block that straddles the limit is not shown in part.
+func dumpBody(head []byte, contentLength int64) string {
+	if int64(len(head)) <= MaxDumpBodySize {
+		return Redact(string(head))
+	}
+	kept := truncateForRedaction(string(head), int(MaxDumpBodySize))
+	dump := Redact(kept)
+	if contentLength > int64(len(kept)) {
+		return dump + fmt.Sprintf("\n[TRUNCATED %d bytes]", contentLength-int64(len(kept)))
+	}
+	return dump + "\n[TRUNCATED]"
+}
+
+// redactHead sanitizes the start line and headers of a dump. Only the start line goes through the whole cleaner since
+// the headers were already masked and reading them as "key: value" pairs would mask the readable parts again. Header
+// values still get the URL and DSN pass, so that a Referer or X-Forwarded-Uri does not leak a token or a password.
+func redactHead(dump []byte) string {
+	line, headers, _ := strings.Cut(string(dump), "\n")
+	headers = applyMatches(headers, findCredentialURLs(headers))
+	return Redact(line) + "\n" + sanitizeInput(headers)
+}
+
+// isSensitiveHeader returns true if the values of the header named name are masked in dumps
+func isSensitiveHeader(name string) bool {
+	for _, header := range SensitiveHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+	return isSecretKey(name)
+}
+
+// maskHeaders returns a copy of header with the values of sensitive headers masked. The scheme of authorization
+// headers, the names of cookies and the attributes of Set-Cookie stay readable.
+func maskHeaders(header http.Header) http.Header {
+	masked := header.Clone()
+	for name, values := range masked {
+		if !isSensitiveHeader(name) {
+			continue
+		}
+		for i, value := range values {
+			switch http.CanonicalHeaderKey(name) {
+			case "Authorization", "Proxy-Authorization":
+				if scheme := authSchemeRegex.FindString(value); len(scheme) > 0 {
+					values[i] = scheme + cleanedPlaceholder
+					continue
+				}
+				values[i] = cleanedPlaceholder
+			case "Cookie":
+				cookies := strings.Split(value, ";")
+				for j, cookie := range cookies {
+					if name, _, found := strings.Cut(cookie, "="); found {
+						cookies[j] = name + "=" + cleanedPlaceholder
+					}
+				}
+				values[i] = strings.Join(cookies, ";")
+			case "Set-Cookie":
+				cookie, attributes, _ := strings.Cut(value, ";")
+				name, _, _ := strings.Cut(cookie, "=")
+				values[i] = name + "=" + cleanedPlaceholder
+				if len(attributes) > 0 {
+					values[i] += ";" + attributes
+				}
+			default:
+				values[i] = cleanedPlaceholder
+			}
+		}
+	}
+	return masked
+}
@@ -0,0 +1,178 @@
+package verbose
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// jsonRule labels the values the structural JSON pass cleans
+const jsonRule = "json"
+
+// maxJSONDepth limits how deeply nested a JSON document may be before the structural pass gives up on it
+const maxJSONDepth = 64
+
+// errInvalidJSON stops the structural pass over text that is not JSON
+var errInvalidJSON = errors.New("invalid JSON")
+
+// jsonScanner walks a single JSON object and records the spans of the values under SensitiveKeys
+type jsonScanner struct {
+	input   string
+	depth   int
+	matches []cleanerMatch
+	values  []cleanerMatch // the strings that are not under SensitiveKeys
+}
+
+// findJSON returns the scalar values under SensitiveKeys, at any depth, of every JSON object in input along with
+// the spans of those objects and of the strings they hold that are left alone. Text that does not parse as JSON is
+// skipped so that the substring rules clean it.
+func findJSON(input string) (matches, regions, values []cleanerMatch) {
+	for i := strings.IndexByte(input, '{'); i != -1; {
+		js := &jsonScanner{input: input}
+		end, err := js.object(i, false)
+		if err == nil {
+			matches = append(matches, js.matches...)
+			values = append(values, js.values...)
+			regions = append(regions, cleanerMatch{start: i, end: end, rule: jsonRule})
+		} else {
+			end = i + 1
+		}
+		next := strings.IndexByte(input[end:], '{')
+		if next == -1 {
+			break
+		}
+		i = end + next
+	}
+	return
+}
+
+// skipSpace returns the index of the first byte at or after i that is not JSON whitespace
+func (js *jsonScanner) skipSpace(i int) int {
+	for i < len(js.input) && strings.IndexByte(" \t\r\n", js.input[i]) != -1 {
+		i++
+	}
+	return i
+}
+
+// value scans the JSON value at i and returns the index after it, recording it when sensitive
+func (js *jsonScanner) value(i int, sensitive bool) (int, error) {
+	i = js.skipSpace(i)
+	if i >= len(js.input) {
+		return i, errInvalidJSON
+	}
+	switch c := js.input[i]; {
+	case c == '{':
+		return js.object(i, sensitive)
+	case c == '[':
+		return js.array(i, sensitive)
+	case c == '"':
+		end, err := js.str(i)
+		if err == nil && end-i > 2 {
+			if sensitive {
+				js.matches = append(js.matches, cleanerMatch{start: i + 1, end: end - 1, rule: jsonRule})
+			} else {
+				js.values = append(js.values, cleanerMatch{start: i + 1, end: end - 1, rule: jsonRule})
+			}
+		}
+		return end, err
+	case c == '-' || c >= '0' && c <= '9':
+		end := i + 1
+		for end < len(js.input) && strings.IndexByte("0123456789.eE+-", js.input[end]) != -1 {
+			end++
+		}
+		if sensitive {
+			js.matches = append(js.matches, cleanerMatch{start: i, end: end, rule: jsonRule, quote: true})
+		}
+		return end, nil
+	default:
+		for _, literal := range []string{"true", "false", "null"} {
+			if strings.HasPrefix(js.input[i:], literal) {
+				return i + len(literal), nil
+			}
+		}
+		return i, errInvalidJSON
+	}
+}
+
+// object scans the JSON object at i and returns the index after its closing brace
+func (js *jsonScanner) object(i int, sensitive bool) (int, error) {
+	if js.depth++; js.depth > maxJSONDepth {
+		return i, errInvalidJSON
+	}
+	defer func() { js.depth-- }()
+	i = js.skipSpace(i + 1)
+	if i < len(js.input) && js.input[i] == '}' {
+		return i + 1, nil
+	}
+	for i < len(js.input) {
+		if js.input[i] != '"' {
+			return i, errInvalidJSON
+		}
+		end, err := js.str(i)
+		if err != nil {
+			return end, err
+		}
+		var key string
+		if err := json.Unmarshal([]byte(js.input[i:end]), &key); err != nil {
+			return end, errInvalidJSON
+		}
+		i = js.skipSpace(end)
+		if i >= len(js.input) || js.input[i] != ':' {
+			return i, errInvalidJSON
+		}
+		if i, err = js.value(i+1, sensitive || IsSensitiveKey(key)); err != nil {
+			return i, err
+		}
+		i = js.skipSpace(i)
+		if i < len(js.input) && js.input[i] == '}' {
+			return i + 1, nil
+		}
+		if i >= len(js.input) || js.input[i] != ',' {
+			return i, errInvalidJSON
+		}
+		i = js.skipSpace(i + 1)
+	}
+	return i, errInvalidJSON
+}
+
+// array scans the JSON array at i and returns the index after its closing bracket
+func (js *jsonScanner) array(i int, sensitive bool) (int, error) {
+	if js.depth++; js.depth > maxJSONDepth {
+		return i, errInvalidJSON
+	}
+	defer func() { js.depth-- }()
+	i = js.skipSpace(i + 1)
+	if i < len(js.input) && js.input[i] == ']' {
+		return i + 1, nil
+	}
+	for i < len(js.input) {
+		var err error
+		if i, err = js.value(i, sensitive); err != nil {
+			return i, err
+		}
+		i = js.skipSpace(i)
+		if i < len(js.input) && js.input[i] == ']' {
+			return i + 1, nil
+		}
+		if i >= len(js.input) || js.input[i] != ',' {
+			return i, errInvalidJSON
+		}
+		i++
+	}
+	return i, errInvalidJSON
+}
+
+// str scans the JSON string at i and returns the index after its closing quote
+func (js *jsonScanner) str(i int) (int, error) {
+	for j := i + 1; j < len(js.input); j++ {
+		switch js.input[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		case '\n':
+			return j, errInvalidJSON
+		}
+	}
+	return len(js.input), errInvalidJSON
+}
@@ -0,0 +1,117 @@
+package verbose
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ManifestEntry describes a registered secret by its SHA512 checksum, never by its value
+type ManifestEntry struct {
+	Label       string `json:"label,omitempty"`
+	Hash        string `json:"hash"`
+	Length      int    `json:"length"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Manifest lists secrets to register without revealing them, so it can be handed to processes like the verbose
+// command that filter the logs of others
+type Manifest struct {
+	Secrets []ManifestEntry `json:"secrets"`
+}
+
+// NewManifestEntry describes secret by its SHA512 checksum and length along with the label and replacement it is
+// registered with. Errors never include the secret.
+func NewManifestEntry(label string, secret SecretBytes, replacement string) (ManifestEntry, error) {
+	if len(secret) < SecretMinLength {
+		return ManifestEntry{}, fmt.Errorf("secret %q of %d bytes is shorter than SecretMinLength %d",
+			label, len(secret), SecretMinLength)
+	}
+	hash, err := secret.Sha512()
+	if err != nil {
+		return ManifestEntry{}, fmt.Errorf("failed to hash secret %q: %w", label, err)
+	}
+	return ManifestEntry{Label: label, Hash: hash, Length: len(secret), Replacement: replacement}, nil
+}
+
+// LoadManifest registers the secrets of a Manifest read from r and returns how many were registered. The plain
+// {"<hash>": length} form of ImportSecrets is accepted as well.
+func LoadManifest(r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return 0, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if len(manifest.Secrets) == 0 && !bytes.Contains(data, []byte(`"secrets"`)) {
+		var hashes map[string]int
+		if err := json.Unmarshal(data, &hashes); err != nil {
+			return 0, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		return ImportSecrets(hashes)
+	}
+	loaded := 0
+	for i, entry := range manifest.Secrets {
+		if err := entry.register(); err != nil {
+			return loaded, fmt.Errorf("manifest entry %d: %w", i, err)
+		}
+		loaded++
+	}
+	return loaded, nil
+}
+
+// LoadManifestFile is LoadManifest of the file at path
+func LoadManifestFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	return LoadManifest(f)
+}
+
+// register validates the entry and commits its hash, replacement and label to the secrets
+func (e ManifestEntry) register() error {
+	hash := strings.ToLower(e.Hash)
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != 128 {
+		return fmt.Errorf("invalid SHA512 hash %q", e.Hash)
+	}
+	if e.Length < SecretMinLength {
+		return fmt.Errorf("length %d is shorter than SecretMinLength %d", e.Length, SecretMinLength)
+	}
+	if err := commitHash(hash, e.Replacement, e.Length); err != nil {
+		return err
+	}
+	if len(e.Label) > 0 {
+		secrets.bmu.Lock()
+		secrets.Labels[hash] = e.Label
+		secrets.bmu.Unlock()
+	}
+	return nil
+}
+
+// HarvestEnv registers the value of every environment variable whose name classifies as secret with IsSecretEnv,
+// labeled with its name, and returns how many were registered. Values shorter than SecretMinLength are skipped.
+func HarvestEnv() (int, error) {
+	harvested := 0
+	for _, kv := range os.Environ() {
+		name, value, found := strings.Cut(kv, "=")
+		if !found || len(value) < SecretMinLength || !isSecretKey(name) {
+			continue
+		}
+		if err := AddSecret(SecretBytes(value), ""); err != nil {
+			return harvested, fmt.Errorf("failed to register %s: %w", name, err)
+		}
+		if err := SetSecretLabel(SecretBytes(value), name); err != nil {
+			return harvested, fmt.Errorf("failed to label %s: %w", name, err)
+		}
+		harvested++
+	}
+	return harvested, nil
+}
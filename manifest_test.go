@@ -0,0 +1,73 @@
+package verbose
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	secret := SecretBytes("manifest-loaded-secret")
+	hash, err := secret.Sha512()
+	if err != nil {
+		t.Fatalf("Sha512() error = %v", err)
+	}
+	defer func() { _ = RemoveSecret(secret) }()
+
+	manifest := `{"secrets": [{"label": "deploy-key", "hash": "` + hash + `", "length": 22, "replacement": "[DEPLOY]"}]}`
+	loaded, err := LoadManifest(strings.NewReader(manifest))
+	if err != nil || loaded != 1 {
+		t.Fatalf("LoadManifest() = %d, %v; want 1, nil", loaded, err)
+	}
+	output, findings := RedactWithReport("key " + string(secret))
+	if output != "key [DEPLOY]" || len(findings) != 1 || findings[0].Rule != "deploy-key" {
+		t.Errorf("RedactWithReport() = %q, %+v", output, findings)
+	}
+
+	if err := RemoveSecret(secret); err != nil {
+		t.Fatalf("RemoveSecret() error = %v", err)
+	}
+	if loaded, err := LoadManifest(strings.NewReader(`{"` + hash + `": 22}`)); err != nil || loaded != 1 {
+		t.Fatalf("LoadManifest() of the plain form = %d, %v; want 1, nil", loaded, err)
+	}
+	if output := Redact("key " + string(secret)); output != "key "+strings.Repeat("*", 22) {
+		t.Errorf("Redact() = %q", output)
+	}
+
+	for _, invalid := range []string{
+		`{"secrets": [{"hash": "abc", "length": 22}]}`,
+		`{"secrets": [{"hash": "` + hash + `", "length": 2}]}`,
+		`not json`,
+	} {
+		if _, err := LoadManifest(strings.NewReader(invalid)); err == nil {
+			t.Errorf("LoadManifest(%s) should fail", invalid)
+		}
+	}
+}
+
+func TestNewManifestEntry(t *testing.T) {
+	entry, err := NewManifestEntry("db", SecretBytes("manifest-entry-secret"), "[DB]")
+	if err != nil {
+		t.Fatalf("NewManifestEntry() error = %v", err)
+	}
+	if entry.Label != "db" || entry.Length != 21 || len(entry.Hash) != 128 || entry.Replacement != "[DB]" {
+		t.Errorf("NewManifestEntry() = %+v", entry)
+	}
+	if _, err := NewManifestEntry("short", SecretBytes("abc"), ""); err == nil || strings.Contains(err.Error(), "abc") {
+		t.Errorf("NewManifestEntry() of a short secret error = %v; want an error without the secret", err)
+	}
+}
+
+func TestHarvestEnv(t *testing.T) {
+	t.Setenv("HARVEST_TEST_TOKEN", "harvested-token-value")
+	t.Setenv("HARVEST_TEST_HOME", "/home/harvest")
+	t.Setenv("GOROOT", "/usr/local/harvest-go")
+	defer func() { _ = RemoveSecret(SecretBytes("harvested-token-value")) }()
+	if _, err := HarvestEnv(); err != nil {
+		t.Fatalf("HarvestEnv() error = %v", err)
+	}
+	_, findings := RedactWithReport("token harvested-token-value in " + os.Getenv("HARVEST_TEST_HOME") + " with " + os.Getenv("GOROOT"))
+	if len(findings) != 1 || findings[0].Rule != "HARVEST_TEST_TOKEN" {
+		t.Errorf("RedactWithReport() findings = %+v; want one HARVEST_TEST_TOKEN finding", findings)
+	}
+}
@@ -0,0 +1,138 @@
+package verbose
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lineCondenser keeps the summary and error lines of a package manager's output and drops everything else
+type lineCondenser struct {
+	keep     []*regexp.Regexp // lines kept on their own
+	sections []*regexp.Regexp // headers whose following lines are kept up to the next blank line
+}
+
+// ruleLineRegex matches the ==== and ---- separators yum and dnf draw around their tables
+var ruleLineRegex = regexp.MustCompile(`^\s*(=+|-+)\s*$`)
+
+// condense returns the lines of input matched by the condenser, keeping the trailing newline of input
+func (lc lineCondenser) condense(input string) string {
+	var b strings.Builder
+	inSection := false
+	for _, line := range strings.Split(strings.TrimSuffix(input, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if len(strings.TrimSpace(line)) == 0 {
+			inSection = false
+			continue
+		}
+		kept := inSection && !ruleLineRegex.MatchString(line)
+		for _, re := range lc.sections {
+			if re.MatchString(line) {
+				inSection, kept = true, true
+				break
+			}
+		}
+		for i := 0; !kept && i < len(lc.keep); i++ {
+			kept = lc.keep[i].MatchString(line)
+		}
+		if kept {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	output := b.String()
+	if !strings.HasSuffix(input, "\n") {
+		output = strings.TrimSuffix(output, "\n")
+	}
+	return output
+}
+
+// yumNoiseRegexes are the yumRegexes that match progress output rather than the summary of a transaction
+var yumNoiseRegexes = []*regexp.Regexp{
+	LastMetadataCheckRegex,
+	DownloadingPackagesRegex,
+	VerifyingRegex,
+}
+
+var yumCondenser = lineCondenser{
+	keep: []*regexp.Regexp{
+		CompleteMessageRegex,
+		regexp.MustCompile(`^(Nothing to do\.|No match for argument: .+|Package .+ is already installed\.)$`),
+	},
+	sections: []*regexp.Regexp{
+		regexp.MustCompile(`^Transaction Summary$`),
+		regexp.MustCompile(`^(Installed|Upgraded|Updated|Removed|Erased|Reinstalled|Downgraded|Failed|Dependency (Installed|Updated)):\s*$`),
+		regexp.MustCompile(`^(Error|Problem)\b.*`),
+	},
+}
+
+var aptCondenser = lineCondenser{
+	keep: []*regexp.Regexp{
+		regexp.MustCompile(`^\d+ upgraded, \d+ newly installed, \d+ to remove and \d+ not upgraded\.$`),
+		regexp.MustCompile(`^(E|Err):.*`),
+		regexp.MustCompile(`^dpkg: error.*`),
+	},
+	sections: []*regexp.Regexp{
+		regexp.MustCompile(`^Errors were encountered while processing:$`),
+	},
+}
+
+var apkCondenser = lineCondenser{
+	keep: []*regexp.Regexp{
+		regexp.MustCompile(`^OK: .* in \d+ packages?$`),
+	},
+	sections: []*regexp.Regexp{
+		regexp.MustCompile(`^ERROR: .*`),
+	},
+}
+
+var pipCondenser = lineCondenser{
+	keep: []*regexp.Regexp{
+		regexp.MustCompile(`^Successfully (installed|uninstalled) .+`),
+	},
+	sections: []*regexp.Regexp{
+		regexp.MustCompile(`^(ERROR|error): .*`),
+	},
+}
+
+var npmCondenser = lineCondenser{
+	keep: []*regexp.Regexp{
+		regexp.MustCompile(`^(added|removed|changed|updated) \d+ packages?.*`),
+		regexp.MustCompile(`^up to date, audited \d+ packages?.*`),
+		regexp.MustCompile(`^(found \d+ |\d+ .*)vulnerabilit(y|ies).*`),
+		regexp.MustCompile(`^npm (ERR!|error) .*`),
+	},
+}
+
+// TruncateYumUpdate condenses the output of yum to the transaction summary, the installed packages and errors
+func TruncateYumUpdate(input string) string {
+	output := input
+	for _, re := range yumNoiseRegexes {
+		output = re.ReplaceAllString(output, "")
+	}
+	return yumCondenser.condense(output)
+}
+
+// TruncateDnfUpdate condenses the output of dnf, which shares its format with yum
+func TruncateDnfUpdate(input string) string {
+	return TruncateYumUpdate(input)
+}
+
+// TruncateAptGet condenses the output of apt-get to its upgraded/installed summary and errors
+func TruncateAptGet(input string) string {
+	return aptCondenser.condense(input)
+}
+
+// TruncateApkAdd condenses the output of apk to its OK summary and errors
+func TruncateApkAdd(input string) string {
+	return apkCondenser.condense(input)
+}
+
+// TruncatePipInstall condenses the output of pip to the installed packages and errors
+func TruncatePipInstall(input string) string {
+	return pipCondenser.condense(input)
+}
+
+// TruncateNpmInstall condenses the output of npm to the added packages, the audit summary and errors
+func TruncateNpmInstall(input string) string {
+	return npmCondenser.condense(input)
+}
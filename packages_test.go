@@ -0,0 +1,208 @@
+package verbose
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const yumOutput = `Last metadata expiration check: 0:01:02 ago on Mon 14 Oct 2024 10:00:00 AM UTC.
+Dependencies resolved.
+================================================================================
+ Package           Arch        Version              Repository         Size
+================================================================================
+Installing:
+ git               x86_64      2.39.3-1.el9         appstream          61 k
+Installing dependencies:
+ perl-Error        noarch      1:0.17029-7.el9      appstream          41 k
+
+Transaction Summary
+================================================================================
+Install  2 Packages
+
+Total download size: 102 k
+Installed size: 200 k
+Downloading Packages:
+(1/2): perl-Error-0.17029-7.el9.noarch.rpm      410 kB/s |  41 kB     00:00
+(2/2): git-2.39.3-1.el9.x86_64.rpm              600 kB/s |  61 kB     00:00
+--------------------------------------------------------------------------------
+Total                                           300 kB/s | 102 kB     00:00
+Running transaction check
+Transaction check succeeded.
+Running transaction
+  Preparing        :                                                        1/1
+  Installing       : perl-Error-1:0.17029-7.el9.noarch                      1/2
+  Installing       : git-2.39.3-1.el9.x86_64                                2/2
+  Verifying        : git-2.39.3-1.el9.x86_64                                1/2
+  Verifying        : perl-Error-1:0.17029-7.el9.noarch                      2/2
+
+Installed:
+  git-2.39.3-1.el9.x86_64          perl-Error-1:0.17029-7.el9.noarch
+
+Complete!
+`
+
+func TestTruncatePackageManagers(t *testing.T) {
+	tests := []struct {
+		name     string
+		truncate func(string) string
+		input    string
+		want     string
+	}{
+		{
+			name:     "yum",
+			truncate: TruncateYumUpdate,
+			input:    yumOutput,
+			want: `Transaction Summary
+Install  2 Packages
+Installed:
+  git-2.39.3-1.el9.x86_64          perl-Error-1:0.17029-7.el9.noarch
+Complete!
+`,
+		},
+		{
+			name:     "dnf error",
+			truncate: TruncateDnfUpdate,
+			input: `Last metadata expiration check: 0:00:10 ago on Mon 14 Oct 2024 10:00:00 AM UTC.
+No match for argument: nosuchpkg
+Error: Unable to find a match: nosuchpkg
+`,
+			want: `No match for argument: nosuchpkg
+Error: Unable to find a match: nosuchpkg
+`,
+		},
+		{
+			name:     "apt-get",
+			truncate: TruncateAptGet,
+			input: `Reading package lists...
+Building dependency tree...
+Reading state information...
+The following NEW packages will be installed:
+  curl
+0 upgraded, 1 newly installed, 0 to remove and 3 not upgraded.
+Need to get 254 kB of archives.
+Get:1 http://deb.debian.org/debian bullseye/main amd64 curl amd64 7.74.0 [254 kB]
+E: Unable to locate package nosuchpkg
+`,
+			want: `0 upgraded, 1 newly installed, 0 to remove and 3 not upgraded.
+E: Unable to locate package nosuchpkg
+`,
+		},
+		{
+			name:     "apk",
+			truncate: TruncateApkAdd,
+			input: `fetch https://dl-cdn.alpinelinux.org/alpine/v3.19/main/x86_64/APKINDEX.tar.gz
+(1/2) Installing ca-certificates (20240226-r0)
+(2/2) Installing curl (8.5.0-r0)
+Executing busybox-1.36.1-r15.trigger
+OK: 12 MiB in 17 packages
+`,
+			want: "OK: 12 MiB in 17 packages\n",
+		},
+		{
+			name:     "pip",
+			truncate: TruncatePipInstall,
+			input: `Collecting requests
+  Downloading requests-2.32.3-py3-none-any.whl (64 kB)
+Requirement already satisfied: idna<4,>=2.5 in /usr/lib/python3/dist-packages (from requests) (3.3)
+Installing collected packages: requests
+Successfully installed requests-2.32.3
+ERROR: Could not find a version that satisfies the requirement nosuchpkg
+ERROR: No matching distribution found for nosuchpkg
+`,
+			want: `Successfully installed requests-2.32.3
+ERROR: Could not find a version that satisfies the requirement nosuchpkg
+ERROR: No matching distribution found for nosuchpkg
+`,
+		},
+		{
+			name:     "npm",
+			truncate: TruncateNpmInstall,
+			input: `npm WARN deprecated inflight@1.0.6: This module is not supported
+npm WARN deprecated glob@7.2.3: Glob versions prior to v9 are no longer supported
+
+added 120 packages, and audited 121 packages in 3s
+
+14 packages are looking for funding
+  run ` + "`npm fund`" + ` for details
+
+found 0 vulnerabilities
+`,
+			want: `added 120 packages, and audited 121 packages in 3s
+found 0 vulnerabilities
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.truncate(tt.input); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRinse(t *testing.T) {
+	if got := RinseAs(ContentYum, yumOutput); got != TruncateYumUpdate(yumOutput) {
+		t.Errorf("RinseAs() = %q; want the yum summary", got)
+	}
+	if got := Rinse(yumOutput); got != yumOutput {
+		t.Errorf("Rinse() = %q; want the yum output rendered, not condensed", got)
+	}
+	log := "line1\nCollecting requests\nline3 important error"
+	if got := Scrub(log); got != log {
+		t.Errorf("Scrub() = %q; want %q, condensing is opt-in", got, log)
+	}
+	line := "Collecting requests\n"
+	if got := Rinse(line); got != line {
+		t.Errorf("Rinse() = %q; a single line should never be condensed", got)
+	}
+	if got := RinseAs(ContentPip, line); got != "" {
+		t.Errorf("RinseAs() = %q; want the declared pip condenser to drop it", got)
+	}
+
+	err := RegisterRinser(Rinser{
+		Name:  "test",
+		Rinse: func(string) string { return "rinsed" },
+	})
+	if err != nil {
+		t.Fatalf("RegisterRinser() error = %v", err)
+	}
+	t.Cleanup(func() { _ = RemoveRinser("test") })
+	if got := RinseAs("test", "\x1b[31mred\x1b[0m"); got != "rinsed" {
+		t.Errorf("RinseAs() = %q; want %q", got, "rinsed")
+	}
+	if err := RegisterRinser(Rinser{Name: "test"}); err == nil {
+		t.Errorf("RegisterRinser() without a Rinse func should fail")
+	}
+}
+
+func TestRinserRegistersRinser(t *testing.T) {
+	err := RegisterRinser(Rinser{
+		Name: "outer",
+		Rinse: func(input string) string {
+			_ = RegisterRinser(Rinser{Name: "inner", Rinse: strings.ToUpper})
+			return input
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterRinser() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = RemoveRinser("outer")
+		_ = RemoveRinser("inner")
+	})
+	done := make(chan string)
+	go func() { done <- RinseAs("outer", "a") + RinseAs("inner", "b") }()
+	select {
+	case got := <-done:
+		if got != "aB" {
+			t.Errorf("RinseAs() = %q; want %q", got, "aB")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RinseAs() deadlocked on a Rinser that registers another one")
+	}
+	if err := RemoveRinser("missing"); err == nil {
+		t.Errorf("RemoveRinser() of an unregistered name should fail")
+	}
+}
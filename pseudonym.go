@@ -0,0 +1,80 @@
+package verbose
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// PseudonymLength is the number of hex characters of the HMAC that Pseudonymize writes into a tag
+var PseudonymLength = 6
+
+// MinPseudonymKeyLength is the number of bytes SetPseudonymKey requires of a key
+const MinPseudonymKeyLength = 16
+
+// pseudonymKey keys the HMAC of Pseudonymize, it is random for every process until SetPseudonymKey replaces it
+var pseudonymKey []byte
+
+// nmu guards pseudonymKey
+var nmu = &sync.RWMutex{}
+
+// pseudonymKeyOnce generates the random pseudonymKey the first time it is needed
+var pseudonymKeyOnce sync.Once
+
+// SetPseudonymKey replaces the key of Pseudonymize so that processes sharing it tag the same secret the same way.
+// The key must be at least MinPseudonymKeyLength bytes long.
+func SetPseudonymKey(key SecretBytes) error {
+	if len(key) < MinPseudonymKeyLength {
+		return fmt.Errorf("pseudonym key of %d bytes is too short ; need at least %d", len(key), MinPseudonymKeyLength)
+	}
+	pseudonymKeyOnce.Do(func() {}) // a key that was set is never replaced by a random one
+	nmu.Lock()
+	defer nmu.Unlock()
+	pseudonymKey = append([]byte(nil), key...)
+	return nil
+}
+
+// currentPseudonymKey returns pseudonymKey, generating a random one when none was set
+func currentPseudonymKey() []byte {
+	pseudonymKeyOnce.Do(func() {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("failed to generate a pseudonym key: %v", err))
+		}
+		nmu.Lock()
+		pseudonymKey = key
+		nmu.Unlock()
+	})
+	nmu.RLock()
+	defer nmu.RUnlock()
+	return pseudonymKey
+}
+
+// Pseudonymize replaces a secret with a stable tag like [SECRET#7f3a9c], the first PseudonymLength hex characters
+// of its HMAC-SHA256 under the key of SetPseudonymKey. The same secret gets the same tag on every line, so one
+// credential can be followed through the logs without being revealed, and the key keeps short secrets from being
+// recovered by brute force like they could be from HashPrefix.
+func Pseudonymize() RedactionStyle {
+	return RedactionFunc(func(secret string) string {
+		return pseudonym(currentPseudonymKey(), secret)
+	})
+}
+
+// PseudonymizeWithKey is Pseudonymize with its own key instead of the one of SetPseudonymKey
+func PseudonymizeWithKey(key SecretBytes) RedactionStyle {
+	key = append(SecretBytes(nil), key...)
+	return RedactionFunc(func(secret string) string {
+		return pseudonym(key, secret)
+	})
+}
+
+// pseudonym returns the tag of secret under key
+func pseudonym(key []byte, secret string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(secret))
+	sum := hex.EncodeToString(mac.Sum(nil))
+	return "[SECRET#" + sum[:max(1, min(PseudonymLength, len(sum)))] + "]"
+}
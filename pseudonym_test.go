@@ -0,0 +1,50 @@
+package verbose
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymize(t *testing.T) {
+	tag := regexp.MustCompile(`^\[SECRET#[0-9a-f]{6}\]$`)
+	style := Pseudonymize()
+	first, again, other := style.Redact("session-abc"), style.Redact("session-abc"), style.Redact("session-xyz")
+	if !tag.MatchString(first) {
+		t.Errorf("Pseudonymize() = %q; want a [SECRET#xxxxxx] tag", first)
+	}
+	if first != again {
+		t.Errorf("Pseudonymize() is not stable: %q != %q", first, again)
+	}
+	if first == other {
+		t.Errorf("Pseudonymize() gave two secrets the same tag %q", first)
+	}
+
+	keyed := PseudonymizeWithKey(SecretBytes("0123456789abcdef0123456789abcdef"))
+	if got, want := keyed.Redact("session-abc"), PseudonymizeWithKey(SecretBytes("0123456789abcdef0123456789abcdef")).Redact("session-abc"); got != want {
+		t.Errorf("PseudonymizeWithKey() differs for the same key: %q != %q", got, want)
+	}
+
+	if err := SetPseudonymKey(SecretBytes("short")); err == nil {
+		t.Errorf("SetPseudonymKey() of a short key should fail")
+	}
+	if err := SetPseudonymKey(SecretBytes("0123456789abcdef0123456789abcdef")); err != nil {
+		t.Fatalf("SetPseudonymKey() error = %v", err)
+	}
+	if got, want := style.Redact("session-abc"), keyed.Redact("session-abc"); got != want {
+		t.Errorf("Pseudonymize() after SetPseudonymKey() = %q; want %q", got, want)
+	}
+}
+
+func TestPseudonymizeCorrelatesLines(t *testing.T) {
+	secret := "pseudonym-session-id"
+	if err := AddSecretWithStyle(SecretBytes(secret), Pseudonymize()); err != nil {
+		t.Fatalf("AddSecretWithStyle() error = %v", err)
+	}
+	defer func() { _ = RemoveSecret(SecretBytes(secret)) }()
+	lines := strings.Split(Redact("login "+secret+"\nfetch "+secret+"\n"), "\n")
+	first, second := strings.TrimPrefix(lines[0], "login "), strings.TrimPrefix(lines[1], "fetch ")
+	if first != second || !strings.HasPrefix(first, "[SECRET#") {
+		t.Errorf("Redact() tags = %q and %q; want the same [SECRET#...] tag", first, second)
+	}
+}
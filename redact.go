@@ -0,0 +1,125 @@
+package verbose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxRedactInputSize caps the number of bytes RedactContext will scrub and sanitize before it falls back
+var MaxRedactInputSize = 1 << 20
+
+// TruncateOversizedInput makes RedactContext keep the redacted head of inputs larger than MaxRedactInputSize
+// instead of replacing them entirely with RedactPlaceholder
+var TruncateOversizedInput = true
+
+// RedactPlaceholder is written in place of any input that could not be redacted within its budget
+var RedactPlaceholder = "[REDACTED: input exceeded redaction budget]"
+
+// RedactFallback reports which fail-closed fallback RedactContext used, if any
+type RedactFallback int
+
+const (
+	FallbackNone      RedactFallback = iota // FallbackNone means the entire input was redacted
+	FallbackTruncated                       // FallbackTruncated means only the redacted head of the input was kept
+	FallbackReplaced                        // FallbackReplaced means the input was replaced by RedactPlaceholder
+)
+
+// String returns the name of the fallback
+func (f RedactFallback) String() string {
+	switch f {
+	case FallbackNone:
+		return "none"
+	case FallbackTruncated:
+		return "truncated"
+	case FallbackReplaced:
+		return "replaced"
+	default:
+		return fmt.Sprintf("RedactFallback(%d)", int(f))
+	}
+}
+
+// Redact runs Scrub and removes every registered secret from the input, in a single pass so that a secret inside
+// a span a cleaner rule replaced is cleaned along with it
+func Redact(input string) string {
+	return RedactAs("", input)
+}
+
+// RedactAs is Redact with input rinsed as contentType, see RinseAs
+func RedactAs(contentType, input string) string {
+	input = RinseAs(contentType, input)
+	matches, _ := redactMatches(context.Background(), input)
+	return applyMatches(input, matches)
+}
+
+// redactMatches returns the spans of input that the cleaner rules and the registered secrets replace, or ctx.Err()
+// when ctx is done before both were found
+func redactMatches(ctx context.Context, input string) ([]cleanerMatch, error) {
+	found, err := findSecrets(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := findMatches(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return append(matches, found...), nil
+}
+
+// RedactContext is Redact bounded by the deadline of ctx and by MaxRedactInputSize. Raw input is never returned
+// when the budget runs out: oversized input is truncated (see TruncateOversizedInput) and input that cannot be
+// redacted before ctx is done is replaced by RedactPlaceholder. The fallback used is returned alongside ctx.Err()
+// when the deadline was the reason.
+func RedactContext(ctx context.Context, input string) (output string, fallback RedactFallback, err error) {
+	if err = ctx.Err(); err != nil {
+		return RedactPlaceholder, FallbackReplaced, err
+	}
+	dropped := 0
+	if MaxRedactInputSize > 0 && len(input) > MaxRedactInputSize {
+		if !TruncateOversizedInput {
+			return RedactPlaceholder, FallbackReplaced, nil
+		}
+		head := truncateForRedaction(input, MaxRedactInputSize)
+		dropped = len(input) - len(head)
+		input, fallback = head, FallbackTruncated
+	}
+	input = Rinse(input)
+	if err = ctx.Err(); err != nil {
+		return RedactPlaceholder, FallbackReplaced, err
+	}
+	matches, err := redactMatches(ctx, input)
+	if err != nil {
+		return RedactPlaceholder, FallbackReplaced, err
+	}
+	output = applyMatches(input, matches)
+	if fallback == FallbackTruncated {
+		output += fmt.Sprintf(" [TRUNCATED %d bytes]", dropped)
+	}
+	return output, fallback, nil
+}
+
+// truncateForRedaction cuts input to at most size bytes, preferring the last line break so that blocks and secrets
+// are not split in half. Without a line break, the longest registered secret is backed off as well so a partial
+// secret can never survive at the cut. A KeyType block that the cut leaves open is dropped from its opening on.
+func truncateForRedaction(input string, size int) string {
+	head := input[:size]
+	if i := strings.LastIndexByte(head, '\n'); i > 0 {
+		head = head[:i]
+	} else {
+		secrets.mmu.RLock()
+		longest := secrets.max
+		secrets.mmu.RUnlock()
+		if longest >= len(head) {
+			return ""
+		}
+		head = head[:len(head)-longest]
+		for len(head) > 0 && !utf8.RuneStart(input[len(head)]) {
+			head = head[:len(head)-1]
+		}
+	}
+	if start := openKeyType(head); start != -1 {
+		head = head[:start]
+	}
+	return head
+}
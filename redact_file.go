@@ -0,0 +1,118 @@
+package verbose
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// RedactFileOptions customize RedactFile
+type RedactFileOptions struct {
+	Rinse  bool   // Rinse renders terminal control sequences like Redact does, instead of only replacing secrets
+	DryRun bool   // DryRun counts the replacements without rewriting the file
+	Backup string // Backup keeps the original file next to the redacted one with this suffix, like ".bak"
+}
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// RedactFile removes the secrets of the cleaner rules and the registered secrets from the file at path, like old
+// log files written before a leak was known, and returns the number of replacements. The file is rewritten through
+// a temporary file that is renamed over it, so readers never see it half redacted, and it keeps its mode and, on
+// unix, its owner and group. Gzip compressed files are recompressed. A file without any secret is left untouched.
+// Stop the writers of the file first, since whatever they write after the rename is lost with the old file.
+func RedactFile(path string, opts RedactFileOptions) (int, error) {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if !info.Mode().IsRegular() {
+		return 0, fmt.Errorf("%s is not a regular file", path)
+	}
+	if opts.DryRun {
+		return redactFileTo(path, io.Discard, opts.Rinse)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".redact-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = os.Remove(temp.Name()) }()
+	count, err := redactFileTo(path, temp, opts.Rinse)
+	if err == nil && count > 0 {
+		err = chownLike(temp, info)
+		if err == nil {
+			err = temp.Chmod(info.Mode().Perm()) // after chownLike, which may clear the setuid and setgid bits
+		}
+		if err == nil {
+			err = temp.Sync()
+		}
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write the redacted %s: %w", path, err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if len(opts.Backup) > 0 {
+		if err := os.Link(path, path+opts.Backup); err != nil {
+			return 0, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// redactFileTo writes the redacted content of the file at path to w, compressed again when the file is gzip
+// compressed, and returns the number of replacements
+func redactFileTo(path string, w io.Writer, rinse bool) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	in := bufio.NewReader(f)
+	magic, _ := in.Peek(len(gzipMagic))
+	var reader io.Reader = in
+	out := bufio.NewWriter(w)
+	var zw *gzip.Writer
+	if string(magic) == string(gzipMagic) {
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read gzip %s: %w", path, err)
+		}
+		defer func() { _ = zr.Close() }()
+		zw = gzip.NewWriter(out)
+		zw.Header = zr.Header
+		reader = zr
+	}
+	var target io.Writer = out
+	if zw != nil {
+		target = zw
+	}
+	rw := NewRedactWriter(target)
+	rw.rinse = rinse
+	if _, err := io.Copy(rw, reader); err != nil {
+		return 0, err
+	}
+	if err := rw.Close(); err != nil {
+		return 0, err
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+	}
+	return rw.Replaced(), out.Flush()
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package verbose
+
+import "os"
+
+// chownLike leaves temp to its creator, files have no unix owner and group to keep here
+func chownLike(temp *os.File, info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package verbose
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRedactFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "verbose.log")
+	content := "[VERBOSE] connecting\n[VERBOSE] export DB_PASSWORD=hunter2\n[VERBOSE] curl --api-token abc123\n"
+	if err := os.WriteFile(path, []byte(content), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := RedactFile(path, RedactFileOptions{DryRun: true})
+	if err != nil || count != 2 {
+		t.Fatalf("RedactFile() dry run = %d, %v; want 2, nil", count, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("RedactFile() dry run changed the file")
+	}
+
+	count, err = RedactFile(path, RedactFileOptions{Backup: ".bak"})
+	if err != nil || count != 2 {
+		t.Fatalf("RedactFile() = %d, %v; want 2, nil", count, err)
+	}
+	want := "[VERBOSE] connecting\n[VERBOSE] export DB_PASSWORD=[CLEANED]\n[VERBOSE] curl --api-token [CLEANED]\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("RedactFile() wrote %q; want %q", data, want)
+	}
+	if backup, _ := os.ReadFile(path + ".bak"); string(backup) != content {
+		t.Errorf("RedactFile() backup = %q; want the original", backup)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0640 {
+		t.Errorf("RedactFile() mode = %v; want 0640", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("RedactFile() left %d files behind; want the log and its backup", len(entries))
+	}
+
+	if count, err := RedactFile(path, RedactFileOptions{}); err != nil || count != 0 {
+		t.Errorf("RedactFile() of a clean file = %d, %v; want 0, nil", count, err)
+	}
+}
+
+func TestRedactFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verbose.log.1.gz")
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, _ = zw.Write([]byte("token ghp_" + "abcdefghijklmnopqrstuvwxyz0123456789" + "\" sent\n"))
+	_ = zw.Close()
+	if err := os.WriteFile(path, compressed.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	count, err := RedactFile(path, RedactFileOptions{})
+	if err != nil || count == 0 {
+		t.Fatalf("RedactFile() = %d, %v; want replacements", count, err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("RedactFile() did not keep the file compressed: %v", err)
+	}
+	data, _ := io.ReadAll(zr)
+	if bytes.Contains(data, []byte("abcdefghijklmnop")) || !bytes.HasPrefix(data, []byte("token ")) {
+		t.Errorf("RedactFile() wrote %q", data)
+	}
+}
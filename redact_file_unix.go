@@ -0,0 +1,30 @@
+//go:build unix
+
+package verbose
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// chownLike gives temp the owner and group of the file described by info, so that renaming temp over a file of
+// another user, like a log of a service redacted by root, does not hand it to whoever ran RedactFile. It fails
+// rather than change the owner of the file when it is not allowed to keep it.
+func chownLike(temp *os.File, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	current, err := temp.Stat()
+	if err != nil {
+		return err
+	}
+	if own, ok := current.Sys().(*syscall.Stat_t); ok && own.Uid == stat.Uid && own.Gid == stat.Gid {
+		return nil
+	}
+	if err := temp.Chown(int(stat.Uid), int(stat.Gid)); err != nil {
+		return fmt.Errorf("failed to keep the owner %d:%d: %w", stat.Uid, stat.Gid, err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package verbose
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestRedactFileKeepsOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the owner of a file takes root")
+	}
+	path := filepath.Join(t.TempDir(), "service.log")
+	if err := os.WriteFile(path, []byte("export DB_PASSWORD=hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(path, 4242, 4343); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := RedactFile(path, RedactFileOptions{}); err != nil || count != 1 {
+		t.Fatalf("RedactFile() = %d, %v; want 1, nil", count, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat := info.Sys().(*syscall.Stat_t); stat.Uid != 4242 || stat.Gid != 4343 {
+		t.Errorf("RedactFile() owner = %d:%d; want 4242:4343", stat.Uid, stat.Gid)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("RedactFile() mode = %v; want 0600", info.Mode().Perm())
+	}
+}
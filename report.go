@@ -0,0 +1,92 @@
+package verbose
+
+import "context"
+
+// Finding describes a span of text that Scrub or Redact replaced, without the secret that was in it
+type Finding struct {
+	File        string `json:"file,omitempty"` // the file of the finding, for findings of Scan
+	Rule        string `json:"rule"`           // the cleaner rule, or the label of a registered secret
+	Start       int    `json:"start"`          // byte offset of the first replaced byte
+	End         int    `json:"end"`            // byte offset after the last replaced byte
+	Line        int    `json:"line"`           // line of Start, starting at 1
+	Column      int    `json:"column"`         // byte column of Start in its line, starting at 1
+	Replacement string `json:"replacement"`    // the text written in place of the span
+}
+
+// ScrubWithReport is Scrub that also returns a Finding for every span it replaced. The offsets point into the
+// input after Rinse, since that is the text Scrub cleans.
+func ScrubWithReport(input string) (string, []Finding) {
+	input = Rinse(input)
+	matches, _ := findMatches(context.Background(), input)
+	return applyWithReport(input, matches)
+}
+
+// ScrubDryRun returns the Findings of the cleaner rules for input without changing it. The input is not rinsed, so
+// the offsets point into input itself and secrets hidden by terminal rewrites are reported too.
+func ScrubDryRun(input string) []Finding {
+	matches, _ := findMatches(context.Background(), input)
+	return findingsOf(input, mergeMatches(matches))
+}
+
+// RedactWithReport is Redact that also returns a Finding for every span it replaced. Registered secrets are
+// reported under the label given with SetSecretLabel. The offsets point into the input after Rinse.
+func RedactWithReport(input string) (string, []Finding) {
+	input = Rinse(input)
+	matches, _ := redactMatches(context.Background(), input)
+	return applyWithReport(input, matches)
+}
+
+// RedactDryRun returns the Findings of the cleaner rules and the registered secrets for input without changing it.
+// Like ScrubDryRun, the offsets point into input itself.
+func RedactDryRun(input string) []Finding {
+	matches, _ := redactMatches(context.Background(), input)
+	return findingsOf(input, mergeMatches(matches))
+}
+
+// uniqueMatches sorts matches and drops the ones that repeat the span and rule of another, keeping the matches of
+// different rules that overlap
+func uniqueMatches(matches []cleanerMatch) []cleanerMatch {
+	sortMatches(matches)
+	unique := matches[:0]
+	for _, m := range matches {
+		if n := len(unique); n > 0 && unique[n-1].start == m.start && unique[n-1].end == m.end && unique[n-1].rule == m.rule {
+			continue
+		}
+		unique = append(unique, m)
+	}
+	return unique
+}
+
+// applyWithReport replaces the matches in input and describes every merged span that was replaced
+func applyWithReport(input string, matches []cleanerMatch) (string, []Finding) {
+	if len(matches) == 0 {
+		return input, nil
+	}
+	merged := mergeMatches(matches)
+	return renderMatches(input, merged), findingsOf(input, merged)
+}
+
+// findingsOf describes the merged matches of input, counting lines as it goes
+func findingsOf(input string, merged []cleanerMatch) []Finding {
+	if len(merged) == 0 {
+		return nil
+	}
+	findings := make([]Finding, 0, len(merged))
+	line, lineStart, scanned := 1, 0, 0
+	for _, m := range merged {
+		for ; scanned < m.start; scanned++ {
+			if input[scanned] == '\n' {
+				line, lineStart = line+1, scanned+1
+			}
+		}
+		findings = append(findings, Finding{
+			Rule:        m.rule,
+			Start:       m.start,
+			End:         m.end,
+			Line:        line,
+			Column:      m.start - lineStart + 1,
+			Replacement: m.replacementText(input[m.start:m.end]),
+		})
+	}
+	return findings
+}
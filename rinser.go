@@ -0,0 +1,115 @@
+package verbose
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Content types of the built-in Rinsers, usable with RinseAs
+const (
+	ContentDocker = "docker"
+	ContentYum    = "yum"
+	ContentDnf    = "dnf"
+	ContentApt    = "apt"
+	ContentApk    = "apk"
+	ContentPip    = "pip"
+	ContentNpm    = "npm"
+)
+
+// Rinser condenses the output of a specific tool down to the lines worth logging. Condensing drops lines, so a
+// Rinser only runs on input whose content type is declared with RinseAs, never on input it merely looks like.
+type Rinser struct {
+	Name  string                    // Name is the content type declared with RinseAs
+	Rinse func(input string) string // Rinse condenses input
+}
+
+// rinsers is the registry used by RinseAs
+var rinsers = []Rinser{
+	{
+		Name:  ContentDocker,
+		Rinse: TruncateDockerBuild,
+	},
+	{
+		Name:  ContentYum,
+		Rinse: TruncateYumUpdate,
+	},
+	{
+		Name:  ContentDnf, // dnf prints the same format as yum
+		Rinse: TruncateDnfUpdate,
+	},
+	{
+		Name:  ContentApt,
+		Rinse: TruncateAptGet,
+	},
+	{
+		Name:  ContentApk,
+		Rinse: TruncateApkAdd,
+	},
+	{
+		Name:  ContentPip,
+		Rinse: TruncatePipInstall,
+	},
+	{
+		Name:  ContentNpm,
+		Rinse: TruncateNpmInstall,
+	},
+}
+
+// rmu guards rinsers
+var rmu = &sync.RWMutex{}
+
+// RegisterRinser adds r to the registry used by RinseAs, replacing any Rinser with the same Name
+func RegisterRinser(r Rinser) error {
+	if len(r.Name) == 0 {
+		return errors.New("rinser needs a Name")
+	}
+	if r.Rinse == nil {
+		return fmt.Errorf("rinser %q needs a Rinse func", r.Name)
+	}
+	rmu.Lock()
+	defer rmu.Unlock()
+	for i := range rinsers {
+		if rinsers[i].Name == r.Name {
+			rinsers[i] = r
+			return nil
+		}
+	}
+	rinsers = append(rinsers, r)
+	return nil
+}
+
+// RemoveRinser removes the Rinser named name from the registry
+func RemoveRinser(name string) error {
+	rmu.Lock()
+	defer rmu.Unlock()
+	for i := range rinsers {
+		if rinsers[i].Name == name {
+			rinsers = append(rinsers[:i:i], rinsers[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("rinser %q is not registered", name)
+}
+
+// RinseAs renders input with RenderTerminal and condenses it with the Rinser registered for contentType. Condensing
+// drops lines, so it only happens when contentType is declared: an empty or unknown contentType only renders input.
+func RinseAs(contentType, input string) (output string) {
+	output = RenderTerminal(input)
+	if len(contentType) == 0 {
+		return
+	}
+	var rinse func(string) string
+	rmu.RLock()
+	for _, r := range rinsers {
+		if r.Name == contentType {
+			rinse = r.Rinse
+			break
+		}
+	}
+	rmu.RUnlock() // a Rinse func may register rinsers of its own
+	if rinse != nil {
+		output = rinse(output)
+	}
+	return
+}
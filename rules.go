@@ -0,0 +1,127 @@
+package verbose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// kmu guards keyTypes
+var kmu = &sync.RWMutex{}
+
+// patternRule is a user defined cleaner rule added with AddPatternRule
+type patternRule struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+}
+
+// patternRules are cleaned by Scrub after the keyTypes
+var patternRules []patternRule
+
+// pmu guards patternRules
+var pmu = &sync.RWMutex{}
+
+// KeyTypes returns a copy of the KeyTypes that Scrub cleans
+func KeyTypes() []KeyType {
+	kmu.RLock()
+	defer kmu.RUnlock()
+	return append([]KeyType(nil), keyTypes...)
+}
+
+// AddKeyType adds an opening/closing combination for Scrub to clean. The Opening must be between
+// MinSecretPatternLength and MaxSecretPatternLength long. An empty Closing cleans to the end of the line.
+func AddKeyType(kt KeyType) error {
+	if err := newSecretPatterns(kt.Opening).Validate(); err != nil {
+		return fmt.Errorf("invalid KeyType Opening %q: %w", kt.Opening, err)
+	}
+	if len(kt.Closing) > MaxSecretPatternLength {
+		return fmt.Errorf("invalid KeyType Closing: %d is longer than MaxSecretPatternLength %d",
+			len(kt.Closing), MaxSecretPatternLength)
+	}
+	kmu.Lock()
+	defer kmu.Unlock()
+	for _, existing := range keyTypes {
+		if existing == kt {
+			return fmt.Errorf("KeyType %q is already defined", kt.Opening)
+		}
+	}
+	keyTypes = append(keyTypes, kt)
+	return nil
+}
+
+// RemoveKeyType stops Scrub from cleaning the opening/closing combination of kt
+func RemoveKeyType(kt KeyType) error {
+	kmu.Lock()
+	defer kmu.Unlock()
+	for i, existing := range keyTypes {
+		if existing == kt {
+			keyTypes = append(keyTypes[:i:i], keyTypes[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("KeyType %q is not defined", kt.Opening)
+}
+
+// AddPatternRule makes Scrub replace every match of re with replacement. The replacement may refer to submatches
+// of re like regexp.Regexp.Expand does, for example "token=$1[CLEANED]", and defaults to [CLEANED] when empty.
+// The expression must be between MinSecretPatternLength and MaxSecretPatternLength long.
+func AddPatternRule(name string, re *regexp.Regexp, replacement string) error {
+	if len(name) == 0 {
+		return errors.New("pattern rule needs a name")
+	}
+	if re == nil {
+		return fmt.Errorf("pattern rule %q needs a regexp", name)
+	}
+	if err := newSecretPatterns(re.String()).Validate(); err != nil {
+		return fmt.Errorf("invalid pattern rule %q: %w", name, err)
+	}
+	if re.MatchString("") {
+		return fmt.Errorf("invalid pattern rule %q: it matches an empty string", name)
+	}
+	pmu.Lock()
+	defer pmu.Unlock()
+	for _, rule := range patternRules {
+		if rule.name == name {
+			return fmt.Errorf("pattern rule %q is already defined", name)
+		}
+	}
+	patternRules = append(patternRules, patternRule{name: name, re: re, replacement: replacement})
+	return nil
+}
+
+// RemovePatternRule removes the pattern rule added as name
+func RemovePatternRule(name string) error {
+	pmu.Lock()
+	defer pmu.Unlock()
+	for i, rule := range patternRules {
+		if rule.name == name {
+			patternRules = append(patternRules[:i:i], patternRules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("pattern rule %q is not defined", name)
+}
+
+// findPatternRules returns every match of the pattern rules in input, or ctx.Err() once ctx is done
+func findPatternRules(ctx context.Context, input string) ([]cleanerMatch, error) {
+	pmu.RLock()
+	rules := append([]patternRule(nil), patternRules...)
+	pmu.RUnlock()
+	var matches []cleanerMatch
+	for _, rule := range rules {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, loc := range rule.re.FindAllStringSubmatchIndex(input, -1) {
+			m := cleanerMatch{start: loc[0], end: loc[1], rule: rule.name}
+			if len(rule.replacement) > 0 {
+				m.replacement = string(rule.re.ExpandString(nil, rule.replacement, input, loc))
+			}
+			matches = append(matches, m)
+		}
+	}
+	return matches, nil
+}
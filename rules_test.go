@@ -0,0 +1,74 @@
+package verbose
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestAddKeyType(t *testing.T) {
+	kt := KeyType{Opening: "acme_tok_", Closing: ""}
+	if err := AddKeyType(kt); err != nil {
+		t.Fatalf("AddKeyType() error = %v", err)
+	}
+	if err := AddKeyType(kt); err == nil {
+		t.Errorf("AddKeyType() should refuse a duplicate KeyType")
+	}
+	if output := Scrub("auth acme_tok_12345\nnext"); output != "auth [CLEANED]\nnext" {
+		t.Errorf("Scrub() = %q; want %q", output, "auth [CLEANED]\nnext")
+	}
+	if err := RemoveKeyType(kt); err != nil {
+		t.Fatalf("RemoveKeyType() error = %v", err)
+	}
+	if err := RemoveKeyType(kt); err == nil {
+		t.Errorf("RemoveKeyType() should fail for an unknown KeyType")
+	}
+	if output := Scrub("auth acme_tok_12345"); output != "auth acme_tok_12345" {
+		t.Errorf("Scrub() = %q; want the removed KeyType to be ignored", output)
+	}
+
+	for _, invalid := range []KeyType{{Opening: ""}, {Opening: "ab"}, {Opening: string(make([]byte, MaxSecretPatternLength+1))}} {
+		if err := AddKeyType(invalid); err == nil {
+			t.Errorf("AddKeyType(%d byte Opening) should fail", len(invalid.Opening))
+		}
+	}
+}
+
+func TestAddPatternRule(t *testing.T) {
+	if err := AddPatternRule("acme-session", regexp.MustCompile(`session=([a-z]+)-[0-9a-f]{8}`), "session=$1-[CLEANED]"); err != nil {
+		t.Fatalf("AddPatternRule() error = %v", err)
+	}
+	defer func() { _ = RemovePatternRule("acme-session") }()
+	if err := AddPatternRule("acme-session", regexp.MustCompile(`other`), ""); err == nil {
+		t.Errorf("AddPatternRule() should refuse a duplicate name")
+	}
+
+	input := "a session=eu-deadbeef b session=us-0badf00d"
+	want := "a session=eu-[CLEANED] b session=us-[CLEANED]"
+	if output := Scrub(input); output != want {
+		t.Errorf("Scrub(%q) = %q; want %q", input, output, want)
+	}
+
+	invalid := map[string]*regexp.Regexp{
+		"":            regexp.MustCompile(`abc`),
+		"nil":         nil,
+		"short":       regexp.MustCompile(`ab`),
+		"empty-match": regexp.MustCompile(`x*`),
+	}
+	for name, re := range invalid {
+		if err := AddPatternRule(name, re, ""); err == nil {
+			t.Errorf("AddPatternRule(%q) should fail", name)
+		}
+	}
+}
+
+func TestSecretPatternsValidate(t *testing.T) {
+	if err := (secretPatterns{}).Validate(); err == nil {
+		t.Errorf("Validate() should fail for an empty map")
+	}
+	if err := newSecretPatterns("abc", "defg").Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (secretPatterns{4: {"abc"}}).Validate(); err == nil {
+		t.Errorf("Validate() should fail when a pattern does not have the length of its key")
+	}
+}
@@ -1,19 +1,41 @@
 package verbose
 
 import (
+	"context"
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+	"runtime"
 	"slices"
-	"sort"
 	"strings"
 	"sync"
 )
 
 // sanitizeInput uses the secrets.max and secrets.min values as substring lengths to sanitize the input string
 func sanitizeInput(input string) string {
+	output, _ := sanitizeInputContext(context.Background(), input)
+	return output
+}
+
+// secretRule labels the registered secrets that were not given a label with SetSecretLabel
+const secretRule = "secret"
+
+// sanitizeInputContext is sanitizeInput that stops hashing substrings once ctx is done. When ctx ends before every
+// substring was checked, the partially sanitized input is discarded and ctx.Err() is returned instead.
+func sanitizeInputContext(ctx context.Context, input string) (string, error) {
+	matches, err := findSecrets(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return applyMatches(input, matches), nil
+}
+
+// findSecrets returns the spans of input whose SHA512 checksum is a registered secret, labeled with the label of
+// the secret and replaced with its replaceWith value. It stops hashing substrings and returns ctx.Err() once ctx
+// is done.
+func findSecrets(ctx context.Context, input string) ([]cleanerMatch, error) {
 	if len(input) == 0 {
-		return input
+		return nil, nil
 	}
 	var substrLengths []int                 // lengths to use for heuristics
 	var mSubstrLen = make(map[int]struct{}) // collect unique lengths
@@ -32,71 +54,86 @@ func sanitizeInput(input string) string {
 
 	// can we proceed?
 	if len(mSubstrLen) == 0 { // any lengths? if none, then
-		return input
+		return nil, nil
 	}
 	for i, _ := range mSubstrLen { // add unique lengths to substrLengths
 		substrLengths = append(substrLengths, i)
 	}
 	slices.Reverse(substrLengths) // reverse them so the longest hashes are calculated first
-	numWorkers := 8192
-	type foundSecret struct {
-		start, end  int
-		replaceWith string
+	positions := 0
+	for _, length := range substrLengths {
+		positions += max(len(input)-length+1, 0)
 	}
-	foundSecrets := make([]foundSecret, 0)
+	// short inputs, like the lines of a RedactWriter, are hashed without starting goroutines
+	workers := min(runtime.GOMAXPROCS(0), positions/hashesPerWorker+1)
+	found := make([][]cleanerMatch, workers)
 	var wg sync.WaitGroup
-	var mu sync.Mutex
-	type dJob struct {
-		start, end int
-		substr     string
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		scan := func() {
+			defer wg.Done()
+			found[w] = hashSubstrings(ctx, input, substrLengths, w, workers)
+		}
+		if w == workers-1 {
+			scan()
+		} else {
+			go scan()
+		}
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	jobs := make(chan dJob, len(input)*len(substrLengths))
-	hash := func() {
-		defer wg.Done()
-		for job := range jobs {
-			hash := sha512.Sum512([]byte(job.substr))
-			hashStr := hex.EncodeToString(hash[:])
+	return slices.Concat(found...), nil
+}
+
+// hashesPerWorker is the number of substrings findSecrets hashes before it spreads the work over another goroutine
+const hashesPerWorker = 1 << 14
+
+// hashSubstrings returns the registered secrets among the substrings of input of the lengths that start in the
+// part of input that worker of workers hashes. It stops once ctx is done.
+func hashSubstrings(ctx context.Context, input string, lengths []int, worker, workers int) []cleanerMatch {
+	var matches []cleanerMatch
+	var encoded [2 * sha512.Size]byte
+	for _, length := range lengths {
+		n := len(input) - length + 1
+		if n <= 0 {
+			continue
+		}
+		part := (n + workers - 1) / workers
+		for start := worker * part; start < min(n, (worker+1)*part); start++ {
+			if start%1024 == 0 && ctx.Err() != nil {
+				return nil
+			}
+			sum := sha512.Sum512([]byte(input[start : start+length]))
+			hex.Encode(encoded[:], sum[:])
 			secrets.hmu.RLock()
-			replaceWith, exists := secrets.Hashes[hashStr]
+			replaceWith, exists := secrets.Hashes[string(encoded[:])]
 			secrets.hmu.RUnlock()
-			if exists {
-				mu.Lock()
-				foundSecrets = append(foundSecrets, foundSecret{
-					start:       job.start,
-					end:         job.end,
-					replaceWith: replaceWith,
-				})
-				mu.Unlock()
+			if !exists {
+				continue
 			}
-		}
-	}
-	wg.Add(numWorkers)
-	for i := 0; i < numWorkers; i++ {
-		go hash()
-	}
-	for _, length := range substrLengths {
-		for start := 0; start <= len(input)-length; start++ {
-			jobs <- dJob{
-				start:  start,
-				end:    start + length,
-				substr: input[start : start+length],
+			hash := string(encoded[:])
+			secrets.bmu.RLock()
+			label, labeled := secrets.Labels[hash]
+			secrets.bmu.RUnlock()
+			if !labeled {
+				label = secretRule
 			}
+			secrets.ymu.RLock()
+			style := secrets.Styles[hash]
+			secrets.ymu.RUnlock()
+			matches = append(matches, cleanerMatch{
+				start:       start,
+				end:         start + length,
+				rule:        label,
+				replacement: replaceWith,
+				masked:      isDefaultMask(replaceWith),
+				style:       style,
+			})
 		}
 	}
-	close(jobs)
-	wg.Wait()
-	sort.Slice(foundSecrets, func(i, j int) bool {
-		return foundSecrets[i].start < foundSecrets[j].start
-	})
-	sanitized, offset := input, 0
-	for _, secret := range foundSecrets {
-		sanitized = sanitized[:secret.start+offset] + // start + offset
-			secret.replaceWith + // insert replaceWith value
-			sanitized[secret.end+offset:] // end + offset
-		// update offset to reflect replaceWith
-		offset += len(secret.replaceWith) - (secret.end - secret.start)
-	}
-	return sanitized
+	return matches
 }
 
 func Sanitize(a ...interface{}) {
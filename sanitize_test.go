@@ -53,3 +53,17 @@ func BenchmarkSanitizeNoSecrets(b *testing.B) {
 		})
 	}
 }
+
+func TestFindSecretsAcrossWorkers(t *testing.T) {
+	secret := "find-secrets-across-workers"
+	if err := AddSecret(SecretBytes(secret), "[FSW]"); err != nil {
+		t.Fatalf("AddSecret() error = %v", err)
+	}
+	defer func() { _ = RemoveSecret(SecretBytes(secret)) }()
+	filler := strings.Repeat("x", 997)
+	input := strings.Repeat(filler+secret, 100)
+	output := sanitizeInput(input)
+	if strings.Contains(output, secret) || strings.Count(output, "[FSW]") != 100 {
+		t.Errorf("sanitizeInput() replaced %d of 100 secrets", strings.Count(output, "[FSW]"))
+	}
+}
@@ -0,0 +1,111 @@
+package verbose
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// ScanMaxFileSize is the size of the largest file Scan reads when ScanOptions.MaxFileSize is not set
+var ScanMaxFileSize int64 = 64 << 20
+
+// ErrSkipped is wrapped by the errors Scan returns for the files it did not scan, binary files and files over the
+// MaxFileSize, so that a scan never passes a file it could not read through
+var ErrSkipped = errors.New("skipped")
+
+// ScanOptions customize Scan
+type ScanOptions struct {
+	Root        string   // Root is the directory or file of fsys to scan, "." when empty
+	Include     []string // Include limits the scan to files whose names match one of these path.Match patterns
+	Exclude     []string // Exclude skips the files and directories whose names match one of these patterns
+	MaxFileSize int64    // MaxFileSize skips larger files with an ErrSkipped, ScanMaxFileSize when zero
+}
+
+// Scan walks fsys and returns a Finding for every registered secret, KeyType, Signatures catalog credential and
+// other cleaner rule hit in its files, with the File set to the path in fsys. Every hit is reported with its own rule,
+// line and column, even inside the span of another rule. Files are scanned as they are stored, without Rinse, so the
+// lines and columns point into them. Gzip compressed files are decompressed. A file that cannot be read or is
+// skipped, because it is binary or too large, does not stop the scan, its error is returned along with the findings.
+func Scan(fsys fs.FS, opts ScanOptions) ([]Finding, error) {
+	root := opts.Root
+	if len(root) == 0 {
+		root = "."
+	}
+	maxSize := opts.MaxFileSize
+	if maxSize == 0 {
+		maxSize = ScanMaxFileSize
+	}
+	var findings []Finding
+	var errs []error
+	walkErr := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if name != root && matchAny(opts.Exclude, d.Name()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || (len(opts.Include) > 0 && !matchAny(opts.Include, d.Name())) {
+			return nil
+		}
+		fileFindings, err := scanFile(fsys, name, maxSize)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		findings = append(findings, fileFindings...)
+		return nil
+	})
+	return findings, errors.Join(append(errs, walkErr)...)
+}
+
+// matchAny returns true if name matches one of patterns
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFile returns the findings of the file named name in fsys, or an ErrSkipped for binary files and files over maxSize
+func scanFile(fsys fs.FS, name string, maxSize int64) ([]Finding, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(io.LimitReader(zr, maxSize+1)); err != nil {
+			return nil, err
+		}
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrSkipped, maxSize)
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) != -1 {
+		return nil, fmt.Errorf("%w: binary file", ErrSkipped)
+	}
+	matches, _ := redactMatches(context.Background(), string(data))
+	findings := findingsOf(string(data), uniqueMatches(matches))
+	for i := range findings {
+		findings[i].File = name
+	}
+	return findings, nil
+}
@@ -3,8 +3,10 @@ package verbose
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
+	"unicode"
 )
 
 // Hashes map stores hashed secrets and their replacement strings
@@ -13,15 +15,25 @@ type Hashes map[string]string
 // Lengths map stores hashes secrets and their original secret string length
 type Lengths map[string]int
 
+// Labels map stores hashed secrets and the label findings report them under
+type Labels map[string]string
+
+// Styles map stores hashed secrets and the RedactionStyle that replaces them instead of their replaceWith value
+type Styles map[string]RedactionStyle
+
 // Secrets describes hashed secrets and their raw lengths
 type Secrets struct {
 	Hashes  Hashes
 	Lengths Lengths
+	Labels  Labels
+	Styles  Styles
 	min     int
 	max     int
 	hmu     *sync.RWMutex
 	lmu     *sync.RWMutex
 	mmu     *sync.RWMutex
+	bmu     *sync.RWMutex
+	ymu     *sync.RWMutex
 }
 
 // Avg returns the average of the Secrets Lengths min and max values. Min/Max are updated everytime AddSecret runs.
@@ -34,9 +46,13 @@ func NewSecrets() *Secrets {
 	return &Secrets{
 		Hashes:  make(Hashes),
 		Lengths: make(Lengths),
+		Labels:  make(Labels),
+		Styles:  make(Styles),
 		lmu:     &sync.RWMutex{},
 		hmu:     &sync.RWMutex{},
 		mmu:     &sync.RWMutex{},
+		bmu:     &sync.RWMutex{},
+		ymu:     &sync.RWMutex{},
 	}
 }
 
@@ -54,7 +70,8 @@ var SecretEnvs = []string{
 	"INTERCOM", "RABBITMQ", "MAILGUN", "TWILIO", "ZENDESK", "SENDGRID", "AUTH0",
 	"JENKINS", "GITLAB", "GITHUB", "GH", "GITEA", "DATADOG", "SENTRY", "PAGERDUTY",
 	"ROLLBAR", "SLACK", "REDIS", "SQL", "ROOT", "MONGO", "CERT", "_PEM", "_PK", "PK_",
-	"PRIVATE_", "SECRET_", "PROTECTED", "_DSN", "DSN_", "_URI", "URI_",
+	"PRIVATE_", "SECRET_", "PROTECTED", "_DSN", "DSN_", "_URI", "URI_", "AUTHORIZATION", "CREDENTIAL",
+	"PASSPHRASE", "COOKIE",
 }
 
 func ImportSecrets(hashes map[string]int) (imported int, err error) {
@@ -80,11 +97,82 @@ func appendError(errs []error, err error) []error {
 	return errs
 }
 
-// IsSecretEnv uses strings.Contains on SecretEnvs against the env string
+// SensitiveKeys defines the keys of structured documents like JSON and YAML whose values are secret. Keys are split
+// into words on '_', '-', '.' and camelCase, and an entry matches one or more consecutive words joined in lower case,
+// so "client-secret", "clientSecret" and "CLIENT_SECRET" match "secret" and "privateKey" matches "privatekey" while
+// "total_tokens" does not match "token". Plurals match only when they are listed, which tokens and keys are not.
+var SensitiveKeys = []string{
+	"password", "passwd", "passphrase", "secret", "token", "privatekey", "apikey", "accesskey", "secretkey",
+	"signingkey", "encryptionkey", "clientkey", "clientkeydata", "sshkey", "credential", "authorization",
+	"authtoken", "cookie", "sessionid", "pwd", "pincode",
+	"passwords", "secrets", "credentials", "cookies",
+}
+
+// SensitiveKeyNames defines the keys whose values are secret only when they are the whole key, like the auth of a
+// docker config or a private: block, since they are common words of other keys like --pin-version and private_ip
+var SensitiveKeyNames = []string{"auth", "pin", "private"}
+
+// IsSensitiveKey returns true if the key of a structured document matches one of the SensitiveKeys, or is one of
+// the SensitiveKeyNames
+func IsSensitiveKey(key string) bool {
+	words := keyWords(key)
+	if len(words) == 1 && slices.Contains(SensitiveKeyNames, words[0]) {
+		return true
+	}
+	for i := range words {
+		joined := ""
+		for _, word := range words[i:] {
+			joined += word
+			for _, k := range SensitiveKeys {
+				if joined == k {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// keyWords splits key into lower case words on '_', '-', '.', spaces and camelCase humps, like APIKey into api and key
+func keyWords(key string) []string {
+	var words []string
+	runes := []rune(key)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && !strings.ContainsRune("_-. ", runes[i]) {
+			if i == start || !unicode.IsUpper(runes[i]) {
+				continue
+			}
+			lowerBefore := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			lowerAfter := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !lowerBefore && !(unicode.IsUpper(runes[i-1]) && lowerAfter) {
+				continue
+			}
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+			continue
+		}
+		if i > start {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+		}
+		start = i + 1
+	}
+	return words
+}
+
+// IsSecretEnv returns true if one of the SecretEnvs starts a word of the env name, at its start or after an
+// underscore, like PASSW in DB_PASSWORD and KUBE in KUBECONFIG but not ROOT in GOROOT. SecretEnvs that start with
+// an underscore, like _PEM, mark their boundary themselves.
 func IsSecretEnv(env string) bool {
 	for _, e := range SecretEnvs {
-		if strings.Contains(env, e) {
-			return true
+		for i, from := 0, 0; len(e) > 0; from = i + 1 {
+			n := strings.Index(env[from:], e)
+			if n == -1 {
+				break
+			}
+			if i = from + n; i == 0 || env[i-1] == '_' || e[0] == '_' {
+				return true
+			}
 		}
 	}
 	return false
@@ -139,6 +227,26 @@ func AddSecret(secret SecretBytes, replaceWith string) (err error) {
 	return commitHash(hexChecksum, replaceWith, len(secret))
 }
 
+// SetSecretLabel names the registered secret in the findings of RedactWithReport, which report it as "secret"
+// otherwise. The label is how a finding tells secrets apart without revealing them.
+func SetSecretLabel(secret SecretBytes, label string) error {
+	hexChecksum, checksumErr := secret.Sha512()
+	if checksumErr != nil {
+		return fmt.Errorf("error in secret.Sha512() caught: %v", checksumErr)
+	}
+	if !IsSecret(hexChecksum) {
+		return fmt.Errorf("error in SetSecretLabel() the secret is not registered with AddSecret")
+	}
+	secrets.bmu.Lock()
+	defer secrets.bmu.Unlock()
+	if len(label) == 0 {
+		delete(secrets.Labels, hexChecksum)
+		return nil
+	}
+	secrets.Labels[hexChecksum] = label
+	return nil
+}
+
 // charsRepeat returns true if c is "aaa" or something like that
 func charsRepeat(c string) bool {
 	fc := c[0]
@@ -189,6 +297,14 @@ func purgeHash(hash string) error {
 		secrets.lmu.Unlock()
 	}
 
+	secrets.bmu.Lock()
+	delete(secrets.Labels, hash)
+	secrets.bmu.Unlock()
+
+	secrets.ymu.Lock()
+	delete(secrets.Styles, hash)
+	secrets.ymu.Unlock()
+
 	secrets.hmu.RLock()
 	_, exists = secrets.Hashes[hash]
 	secrets.hmu.RUnlock()
@@ -45,7 +45,63 @@ func TestRemoveSecret(t *testing.T) {
 }
 
 func TestIsSecretEnv(t *testing.T) {
+	tests := map[string]bool{
+		"DB_PASSWORD":          true,
+		"MYSQL_ROOT_PASSWORD":  true,
+		"ROOT_TOKEN":           true,
+		"KUBECONFIG":           true,
+		"GITHUB_TOKEN":         true,
+		"SSL_CERT_PEM":         true,
+		"GOROOT":               false,
+		"GOPATH":               false,
+		"HOME":                 false,
+		"TERM":                 false,
+		"XDG_RUNTIME_DIR":      false,
+		"DOCKER_HOST":          false,
+		"NOT_A_SECRETIVE_NAME": false,
+	}
+	for env, want := range tests {
+		if got := IsSecretEnv(env); got != want {
+			t.Errorf("IsSecretEnv(%q) = %v; want %v", env, got, want)
+		}
+	}
+}
 
+func TestIsSensitiveKey(t *testing.T) {
+	tests := map[string]bool{
+		"password":        true,
+		"DB_PASSWORD":     true,
+		"client-secret":   true,
+		"clientSecret":    true,
+		"privateKey":      true,
+		"APIKey":          true,
+		"x-api-key":       true,
+		"client-key-data": true,
+		"session.id":      true,
+		"secrets":         true,
+		"total_tokens":    false,
+		"max_tokens":      false,
+		"maxTokens":       false,
+		"keyboard":        false,
+		"monkey":          false,
+		"author":          false,
+		"height":          false,
+		"auth":            true,
+		"pin":             true,
+		"private":         true,
+		"private_ip":      false,
+		"pinCode":         true,
+		"auth_token":      true,
+		"sort-key":        false,
+		"pin-version":     false,
+		"Key":             false,
+		"author_name":     false,
+	}
+	for key, want := range tests {
+		if got := IsSensitiveKey(key); got != want {
+			t.Errorf("IsSensitiveKey(%q) = %v; want %v", key, got, want)
+		}
+	}
 }
 
 func TestAddSecret(t *testing.T) {
@@ -0,0 +1,197 @@
+package verbose
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Signature describes the format of a well-known provider credential that Scrub cleans wherever it appears
+type Signature struct {
+	Name     string                  // Name identifies the signature for EnableSignature and DisableSignature
+	Pattern  *regexp.Regexp          // Pattern matches the credential, which must not touch other token characters
+	Validate func(token string) bool // Validate checks the checksum of the credential when the provider defines one
+}
+
+// signatures is the catalog of well-known credential formats
+var signatures = []Signature{
+	{Name: "aws-access-key-id", Pattern: regexp.MustCompile(`(?:AKIA|ASIA)[A-Z2-7]{16}`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`xox[abprs]-[0-9A-Za-z]+(?:-[0-9A-Za-z]+)+`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`gh[pousr]_[0-9A-Za-z]{36}`), Validate: validCRC32Token},
+	{Name: "github-fine-grained-pat", Pattern: regexp.MustCompile(`github_pat_[0-9A-Za-z]{22}_[0-9A-Za-z]{59}`)},
+	{Name: "google-api-key", Pattern: regexp.MustCompile(`AIza[0-9A-Za-z_-]{35}`)},
+	{Name: "stripe-secret-key", Pattern: regexp.MustCompile(`sk_live_[0-9A-Za-z]{24,247}`)},
+	{Name: "stripe-restricted-key", Pattern: regexp.MustCompile(`rk_live_[0-9A-Za-z]{24,247}`)},
+	{Name: "stripe-publishable-key", Pattern: regexp.MustCompile(`pk_live_[0-9A-Za-z]{24,247}`)},
+	{Name: "twilio-api-key", Pattern: regexp.MustCompile(`SK[0-9a-f]{32}`)},
+	{Name: "sendgrid-api-key", Pattern: regexp.MustCompile(`SG\.[0-9A-Za-z_-]{22}\.[0-9A-Za-z_-]{43}`)},
+	{Name: "npm-token", Pattern: regexp.MustCompile(`npm_[0-9A-Za-z]{36}`), Validate: validCRC32Token},
+	{Name: "jwt", Pattern: regexp.MustCompile(`eyJ[0-9A-Za-z_-]+\.eyJ[0-9A-Za-z_-]*\.[0-9A-Za-z_-]*`), Validate: validJWT},
+}
+
+// disabledSignatures holds the names of the signatures that Scrub skips
+var disabledSignatures = make(map[string]struct{})
+
+// smu guards signatures and disabledSignatures
+var smu = &sync.RWMutex{}
+
+// Signatures returns the names of every signature in the catalog
+func Signatures() []string {
+	smu.RLock()
+	defer smu.RUnlock()
+	names := make([]string, 0, len(signatures))
+	for _, sig := range signatures {
+		names = append(names, sig.Name)
+	}
+	return names
+}
+
+// AddSignature adds a credential format to the catalog
+func AddSignature(sig Signature) error {
+	if len(sig.Name) == 0 {
+		return errors.New("signature needs a Name")
+	}
+	if sig.Pattern == nil {
+		return fmt.Errorf("signature %q needs a Pattern", sig.Name)
+	}
+	if err := newSecretPatterns(sig.Pattern.String()).Validate(); err != nil {
+		return fmt.Errorf("invalid signature %q: %w", sig.Name, err)
+	}
+	smu.Lock()
+	defer smu.Unlock()
+	for _, existing := range signatures {
+		if existing.Name == sig.Name {
+			return fmt.Errorf("signature %q is already defined", sig.Name)
+		}
+	}
+	signatures = append(signatures, sig)
+	return nil
+}
+
+// RemoveSignature removes a credential format from the catalog
+func RemoveSignature(name string) error {
+	smu.Lock()
+	defer smu.Unlock()
+	for i, existing := range signatures {
+		if existing.Name == name {
+			signatures = append(signatures[:i:i], signatures[i+1:]...)
+			delete(disabledSignatures, name)
+			return nil
+		}
+	}
+	return fmt.Errorf("signature %q is not defined", name)
+}
+
+// EnableSignature turns a signature of the catalog back on
+func EnableSignature(name string) error {
+	smu.Lock()
+	defer smu.Unlock()
+	if !hasSignature(name) {
+		return fmt.Errorf("signature %q is not defined", name)
+	}
+	delete(disabledSignatures, name)
+	return nil
+}
+
+// DisableSignature stops Scrub from cleaning the credentials of a signature
+func DisableSignature(name string) error {
+	smu.Lock()
+	defer smu.Unlock()
+	if !hasSignature(name) {
+		return fmt.Errorf("signature %q is not defined", name)
+	}
+	disabledSignatures[name] = struct{}{}
+	return nil
+}
+
+// SignatureEnabled returns true if Scrub cleans the credentials of the named signature
+func SignatureEnabled(name string) bool {
+	smu.RLock()
+	defer smu.RUnlock()
+	_, disabled := disabledSignatures[name]
+	return hasSignature(name) && !disabled
+}
+
+// hasSignature returns true if the catalog defines name, callers must hold smu
+func hasSignature(name string) bool {
+	for _, sig := range signatures {
+		if sig.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findSignatures returns every credential of an enabled signature in input
+func findSignatures(input string) []cleanerMatch {
+	smu.RLock()
+	enabled := make([]Signature, 0, len(signatures))
+	for _, sig := range signatures {
+		if _, disabled := disabledSignatures[sig.Name]; !disabled {
+			enabled = append(enabled, sig)
+		}
+	}
+	smu.RUnlock()
+	var matches []cleanerMatch
+	for _, sig := range enabled {
+		for _, loc := range sig.Pattern.FindAllStringIndex(input, -1) {
+			if !tokenBoundary(input, loc[0], loc[1]) {
+				continue
+			}
+			if sig.Validate != nil && !sig.Validate(input[loc[0]:loc[1]]) {
+				continue
+			}
+			matches = append(matches, cleanerMatch{start: loc[0], end: loc[1], rule: sig.Name})
+		}
+	}
+	return matches
+}
+
+// isTokenByte returns true for the bytes credentials are made of
+func isTokenByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-'
+}
+
+// tokenBoundary returns true if input[start:end] is not part of a longer token
+func tokenBoundary(input string, start, end int) bool {
+	return (start == 0 || !isTokenByte(input[start-1])) && (end == len(input) || !isTokenByte(input[end]))
+}
+
+// base62Alphabet is the alphabet GitHub and npm use to encode the checksum of their tokens
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// validCRC32Token checks GitHub and npm tokens, whose last 6 characters are the base62 encoded CRC32 checksum of
+// the 30 random characters that follow the prefix
+func validCRC32Token(token string) bool {
+	_, body, found := strings.Cut(token, "_")
+	if !found || len(body) != 36 {
+		return false
+	}
+	checksum := crc32.ChecksumIEEE([]byte(body[:30]))
+	encoded := make([]byte, 6)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		encoded[i] = base62Alphabet[checksum%62]
+		checksum /= 62
+	}
+	return string(encoded) == body[30:]
+}
+
+// validJWT checks that the header of a JSON Web Token is a JSON object naming its algorithm
+func validJWT(token string) bool {
+	header, _, _ := strings.Cut(token, ".")
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(header, "="))
+	if err != nil {
+		return false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(decoded, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["alg"]
+	return ok
+}
@@ -0,0 +1,156 @@
+package verbose
+
+import (
+	"bufio"
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSourceCacheSize is the number of source files whose lines the traces keep cached
+const DefaultSourceCacheSize = 64
+
+// sourceFile is the line index of a source file as it was when it was read
+type sourceFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+	starts  []int64        // the offset of the start of every line
+	lines   map[int]string // the lines read so far
+}
+
+// sourceCache keeps the line indexes of the most recently traced source files
+type sourceCache struct {
+	mu       *sync.Mutex
+	capacity int
+	order    *list.List // most recently used first, of *sourceFile
+	files    map[string]*list.Element
+}
+
+// sources caches the source files of getLineContent
+var sources = newSourceCache(DefaultSourceCacheSize)
+
+// newSourceCache returns an empty sourceCache that holds up to capacity files
+func newSourceCache(capacity int) *sourceCache {
+	return &sourceCache{
+		mu:       &sync.Mutex{},
+		capacity: capacity,
+		order:    list.New(),
+		files:    make(map[string]*list.Element),
+	}
+}
+
+// SetSourceCacheSize sets the number of source files whose lines the traces keep cached, evicting the least
+// recently used ones beyond it. Zero disables the cache so every trace reads the files again.
+func SetSourceCacheSize(size int) {
+	sources.mu.Lock()
+	defer sources.mu.Unlock()
+	sources.capacity = max(size, 0)
+	sources.evict()
+}
+
+// evict removes the least recently used files beyond the capacity
+func (c *sourceCache) evict() {
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.files, oldest.Value.(*sourceFile).path)
+	}
+}
+
+// line returns line lineNum of the file at path, indexing the file again when its modification time or size
+// changed since it was cached
+func (c *sourceCache) line(path string, lineNum int) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	c.mu.Lock()
+	var sf *sourceFile
+	if element, exists := c.files[path]; exists {
+		cached := element.Value.(*sourceFile)
+		if cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			c.order.MoveToFront(element)
+			sf = cached
+			if content, read := sf.lines[lineNum]; read {
+				c.mu.Unlock()
+				return content, nil
+			}
+		} else {
+			c.order.Remove(element)
+			delete(c.files, path)
+		}
+	}
+	c.mu.Unlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	if sf == nil {
+		if sf, err = indexSourceFile(file, path, info); err != nil {
+			return "", err
+		}
+	}
+	content, err := sf.read(file, lineNum)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sf.lines[lineNum] = content
+	if _, exists := c.files[path]; !exists && c.capacity > 0 {
+		c.files[path] = c.order.PushFront(sf)
+		c.evict()
+	}
+	return content, nil
+}
+
+// indexSourceFile records where every line of file starts, however long the lines are
+func indexSourceFile(file *os.File, path string, info os.FileInfo) (*sourceFile, error) {
+	sf := &sourceFile{path: path, modTime: info.ModTime(), size: info.Size(), starts: []int64{0}, lines: make(map[int]string)}
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		offset += int64(len(chunk))
+		if err == nil {
+			sf.starts = append(sf.starts, offset)
+			continue
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		return nil, fmt.Errorf("error scanning file: %w", err)
+	}
+	sf.size = offset
+	if sf.starts[len(sf.starts)-1] == offset {
+		sf.starts = sf.starts[:len(sf.starts)-1] // nothing follows the last line break
+	}
+	return sf, nil
+}
+
+// read returns line lineNum of file with its surrounding space trimmed
+func (sf *sourceFile) read(file io.ReaderAt, lineNum int) (string, error) {
+	if lineNum > len(sf.starts) {
+		return "", fmt.Errorf("line %d not found in file", lineNum)
+	}
+	start, end := sf.starts[lineNum-1], sf.size
+	if lineNum < len(sf.starts) {
+		end = sf.starts[lineNum]
+	}
+	buf := make([]byte, end-start)
+	if _, err := file.ReadAt(buf, start); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("error scanning file: %w", err)
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
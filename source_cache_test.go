@@ -0,0 +1,70 @@
+package verbose
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGetLineContent(t *testing.T) {
+	defer SetSourceCacheSize(DefaultSourceCacheSize)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "source.go")
+	long := strings.Repeat("x", 100<<10)
+	if err := os.WriteFile(path, []byte("package main\n\t"+long+"\r\n  last line"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line    int
+		want    string
+		wantErr bool
+	}{
+		{line: 1, want: "package main"},
+		{line: 2, want: long},
+		{line: 3, want: "last line"},
+		{line: 4, wantErr: true},
+		{line: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := getLineContent(path, tt.line)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("getLineContent(%d) = %.20q, %v; want %.20q", tt.line, got, err, tt.want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("package changed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := getLineContent(path, 1); got != "package changed" {
+		t.Errorf("getLineContent() after a change = %q; want the new line", got)
+	}
+
+	other := filepath.Join(dir, "other.go")
+	if err := os.WriteFile(other, []byte("package other\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	SetSourceCacheSize(1)
+	_, _ = getLineContent(path, 1)
+	_, _ = getLineContent(other, 1)
+	sources.mu.Lock()
+	_, cachedPath := sources.files[path]
+	_, cachedOther := sources.files[other]
+	sources.mu.Unlock()
+	if cachedPath || !cachedOther {
+		t.Errorf("SetSourceCacheSize(1) kept %v and %v; want only the most recent file", cachedPath, cachedOther)
+	}
+
+	SetSourceCacheSize(0)
+	if got, _ := getLineContent(other, 1); got != "package other" || sources.order.Len() != 0 {
+		t.Errorf("getLineContent() without a cache = %q with %d cached files", got, sources.order.Len())
+	}
+}
+
+func BenchmarkGetLineContent(b *testing.B) {
+	_, file, _, _ := runtime.Caller(0)
+	for i := 0; i < b.N; i++ {
+		_, _ = getLineContent(file, 10)
+	}
+}
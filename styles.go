@@ -0,0 +1,184 @@
+package verbose
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RedactionStyle decides the text that replaces a secret
+type RedactionStyle interface {
+	Redact(secret string) string
+}
+
+// RedactionFunc adapts a function to a RedactionStyle
+type RedactionFunc func(secret string) string
+
+// Redact returns f(secret)
+func (f RedactionFunc) Redact(secret string) string {
+	return f(secret)
+}
+
+// globalStyle replaces every secret and cleaner hit that has no RedactionStyle of its own when set
+var globalStyle RedactionStyle
+
+// ruleStyles are the RedactionStyles of cleaner rules, KeyTypes and secret labels by name
+var ruleStyles = make(map[string]RedactionStyle)
+
+// tmu guards globalStyle and ruleStyles
+var tmu = &sync.RWMutex{}
+
+// SetRedactionStyle sets the RedactionStyle of every secret and cleaner hit that has no style or replacement of its
+// own, taking precedence over the asterisk mask of AddSecret and the [CLEANED] placeholder. A secret added with a
+// replaceWith keeps it, like a pattern rule with a replacement does. A nil style restores them.
+func SetRedactionStyle(style RedactionStyle) {
+	tmu.Lock()
+	defer tmu.Unlock()
+	globalStyle = style
+}
+
+// SetRuleStyle sets the RedactionStyle of the cleaner rule named rule, like "json" or "aws-access-key-id", or of
+// the registered secrets labeled rule with SetSecretLabel. A nil style removes it.
+func SetRuleStyle(rule string, style RedactionStyle) {
+	tmu.Lock()
+	defer tmu.Unlock()
+	if style == nil {
+		delete(ruleStyles, rule)
+		return
+	}
+	ruleStyles[rule] = style
+}
+
+// SetKeyTypeStyle sets the RedactionStyle of the blocks kt cleans. A nil style removes it.
+func SetKeyTypeStyle(kt KeyType, style RedactionStyle) {
+	SetRuleStyle(kt.Opening, style)
+}
+
+// AddSecretWithStyle is AddSecret with a RedactionStyle that replaces the secret instead of a fixed replaceWith
+func AddSecretWithStyle(secret SecretBytes, style RedactionStyle) error {
+	if err := AddSecret(secret, ""); err != nil {
+		return err
+	}
+	if len(secret) == 0 || style == nil {
+		return nil
+	}
+	hexChecksum, checksumErr := secret.Sha512()
+	if checksumErr != nil {
+		return fmt.Errorf("error in secret.Sha512() caught: %v", checksumErr)
+	}
+	secrets.ymu.Lock()
+	defer secrets.ymu.Unlock()
+	secrets.Styles[hexChecksum] = style
+	return nil
+}
+
+// redactionStyle returns the RedactionStyle of the match: its own, the one of its rule, or the global one unless
+// the match brings a replacement of its own like the pattern rules of AddPatternRule and the secrets added with a
+// replaceWith do
+func (m cleanerMatch) redactionStyle() RedactionStyle {
+	if m.mixed {
+		return nil
+	}
+	if m.style != nil {
+		return m.style
+	}
+	tmu.RLock()
+	defer tmu.RUnlock()
+	if style, exists := ruleStyles[m.rule]; exists {
+		return style
+	}
+	if len(m.replacement) > 0 && !m.masked {
+		return nil
+	}
+	return globalStyle
+}
+
+// styleSource names where the RedactionStyle or replacement of a match without a style of its own comes from
+func (m cleanerMatch) styleSource() string {
+	tmu.RLock()
+	_, ruled := ruleStyles[m.rule]
+	tmu.RUnlock()
+	switch {
+	case ruled:
+		return "rule " + m.rule
+	case len(m.replacement) > 0 && !m.masked:
+		return "replacement " + m.replacement
+	}
+	return "global"
+}
+
+// sameStyle returns true if m and other are redacted the same way. Styles of their own are functions that cannot be
+// compared, so they only count as the same for the same rule over the same span.
+func (m cleanerMatch) sameStyle(other cleanerMatch) bool {
+	if m.style != nil || other.style != nil {
+		return m.style != nil && other.style != nil && m.rule == other.rule && m.start == other.start &&
+			m.end == other.end
+	}
+	return m.styleSource() == other.styleSource()
+}
+
+// isDefaultMask returns true if replaceWith is the asterisk mask AddSecret defaults to rather than a replacement
+// chosen by the caller
+func isDefaultMask(replaceWith string) bool {
+	return len(replaceWith) > 0 && replaceWith[0] == '*' && charsRepeat(replaceWith)
+}
+
+// FixedText replaces every secret with text
+func FixedText(text string) RedactionStyle {
+	return RedactionFunc(func(string) string {
+		return text
+	})
+}
+
+// LengthPreserving replaces every character of a secret with a *
+func LengthPreserving() RedactionStyle {
+	return RedactionFunc(func(secret string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(secret))
+	})
+}
+
+// KeepLast replaces every character of a secret but the last n with a *, like ****1234. Secrets shorter than
+// twice n are masked entirely so that most of a short secret is never revealed.
+func KeepLast(n int) RedactionStyle {
+	return RedactionFunc(func(secret string) string {
+		runes := []rune(secret)
+		if n <= 0 || len(runes) < 2*n {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-n) + string(runes[len(runes)-n:])
+	})
+}
+
+// FormatPreserving replaces upper case letters with X, other letters with x and digits with 0, keeping separators
+// and any other character so that the shape of a secret like a card number or a UUID stays readable
+func FormatPreserving() RedactionStyle {
+	return RedactionFunc(func(secret string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case unicode.IsUpper(r):
+				return 'X'
+			case unicode.IsLetter(r):
+				return 'x'
+			case unicode.IsDigit(r):
+				return '0'
+			default:
+				return r
+			}
+		}, secret)
+	})
+}
+
+// HashPrefix replaces a secret with the first n hex characters of its SHA512 checksum, like [sha512:1f40fc92], so
+// that the same secret can be recognized across lines without revealing it. The checksum is not keyed, so short or
+// guessable secrets can be recovered from it by brute force, which Pseudonymize prevents.
+func HashPrefix(n int) RedactionStyle {
+	n = max(1, min(n, sha512.Size*2))
+	return RedactionFunc(func(secret string) string {
+		sum := sha512.Sum512([]byte(secret))
+		return "[sha512:" + hex.EncodeToString(sum[:])[:n] + "]"
+	})
+}
@@ -0,0 +1,89 @@
+package verbose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactionStyles(t *testing.T) {
+	tests := []struct {
+		name   string
+		style  RedactionStyle
+		secret string
+		want   string
+	}{
+		{name: "fixed text", style: FixedText("<hidden>"), secret: "hunter2", want: "<hidden>"},
+		{name: "length preserving", style: LengthPreserving(), secret: "pässword", want: "********"},
+		{name: "keep last", style: KeepLast(4), secret: "4111111111111234", want: "************1234"},
+		{name: "keep last short secret", style: KeepLast(4), secret: "1234567", want: "*******"},
+		{name: "format preserving", style: FormatPreserving(), secret: "AKIA-1234-abcd_Z9", want: "XXXX-0000-xxxx_X0"},
+		{name: "hash prefix", style: HashPrefix(8), secret: "abc", want: "[sha512:ddaf35a1]"},
+		{name: "func", style: RedactionFunc(strings.ToUpper), secret: "shout", want: "SHOUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.style.Redact(tt.secret); got != tt.want {
+				t.Errorf("Redact(%q) = %q; want %q", tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactionStylePrecedence(t *testing.T) {
+	styled, plain, masked := "4111-1111-1111-1234", "plain-registered-secret", "masked-registered-secret"
+	if err := AddSecretWithStyle(SecretBytes(styled), KeepLast(4)); err != nil {
+		t.Fatalf("AddSecretWithStyle() error = %v", err)
+	}
+	defer func() { _ = RemoveSecret(SecretBytes(styled)) }()
+	if err := AddSecret(SecretBytes(plain), "[PLAIN]"); err != nil {
+		t.Fatalf("AddSecret() error = %v", err)
+	}
+	defer func() { _ = RemoveSecret(SecretBytes(plain)) }()
+	if err := AddSecret(SecretBytes(masked), ""); err != nil {
+		t.Fatalf("AddSecret() error = %v", err)
+	}
+	defer func() { _ = RemoveSecret(SecretBytes(masked)) }()
+
+	input := "card " + styled + " key " + plain + " mask " + masked + " DB_PASSWORD=hunter2 {\"token\": \"abcdef\"}"
+	want := "card ***************1234 key [PLAIN] mask " + strings.Repeat("*", 36) +
+		" DB_PASSWORD=[CLEANED] {\"token\": \"[CLEANED]\"}"
+	if output := Redact(input); output != want {
+		t.Errorf("Redact() = %q; want %q", output, want)
+	}
+
+	SetRedactionStyle(FixedText("<hidden>"))
+	defer SetRedactionStyle(nil)
+	SetRuleStyle(jsonRule, FormatPreserving())
+	defer SetRuleStyle(jsonRule, nil)
+	want = "card ***************1234 key [PLAIN] mask <hidden> DB_PASSWORD=<hidden> {\"token\": \"xxxxxx\"}"
+	if output := Redact(input); output != want {
+		t.Errorf("Redact() with styles = %q; want %q", output, want)
+	}
+
+	kt := KeyType{Opening: "SHA256:", Closing: "\n"}
+	SetKeyTypeStyle(kt, FixedText("SHA256:<fingerprint>"))
+	defer SetKeyTypeStyle(kt, nil)
+	if output := Scrub("host key SHA256:abcdefg\n"); output != "host key SHA256:<fingerprint>\n" {
+		t.Errorf("Scrub() with a KeyType style = %q", output)
+	}
+}
+
+func TestMergeMatchesMixedStyles(t *testing.T) {
+	SetRuleStyle("first", FixedText("<first>"))
+	defer SetRuleStyle("first", nil)
+	input := "prefix overlapping secret suffix"
+	matches := []cleanerMatch{
+		{start: 7, end: 18, rule: "first"},
+		{start: 12, end: 25, rule: "second"},
+	}
+	if output := applyMatches(input, matches); output != "prefix [CLEANED] suffix" {
+		t.Errorf("applyMatches() = %q; want the placeholder for matches with different styles", output)
+	}
+	matches = []cleanerMatch{
+		{start: 7, end: 18, rule: "first"},
+		{start: 12, end: 25, rule: "first"},
+	}
+	if output := applyMatches(input, matches); output != "prefix <first> suffix" {
+		t.Errorf("applyMatches() = %q; want the style shared by the merged matches", output)
+	}
+}
@@ -0,0 +1,192 @@
+package verbose
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// terminalWidth caps the column the cursor can be moved to, so that a hostile sequence like ESC [ 99999999 C
+	// cannot make RenderTerminal pad a line out to gigabytes
+	terminalWidth = 4096
+	// terminalRowsBelow caps how many lines below the last one the cursor can be moved to
+	terminalRowsBelow = 24
+)
+
+// terminal is the screen RenderTerminal prints to. Every line ever printed stays on it so that cursor movement
+// rewrites lines in place instead of scrolling them away.
+type terminal struct {
+	lines    [][]string // the cells of each line, a cell holds the bytes of one rune
+	row, col int
+	saved    [2]int // the cursor saved by ESC 7
+}
+
+// RenderTerminal returns input the way a terminal shows it once everything was printed. Carriage returns,
+// backspaces, cursor movement and erase sequences rewrite the lines they target so that only the last state of a
+// progress bar is kept, while colours, titles, hyperlinks and any other escape or control byte are dropped.
+// Clearing the whole screen is ignored so that the history of a log survives it.
+func RenderTerminal(input string) string {
+	if !hasControlBytes(input) {
+		return input
+	}
+	t := &terminal{lines: [][]string{nil}}
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == 0x1b:
+			i = t.escape(input, i+1)
+			continue
+		case c == '\n':
+			t.row, t.col = t.row+1, 0
+			t.grow()
+		case c == '\r':
+			t.col = 0
+		case c == '\b':
+			if t.col > 0 {
+				t.col--
+			}
+		case c == '\t':
+			t.put("\t")
+		case c < 0x20 || c == 0x7f:
+		case c < utf8.RuneSelf:
+			t.put(input[i : i+1])
+		default:
+			_, size := utf8.DecodeRuneInString(input[i:])
+			t.put(input[i : i+size])
+			i += size
+			continue
+		}
+		i++
+	}
+	var b strings.Builder
+	b.Grow(len(input))
+	for row, line := range t.lines {
+		if row > 0 {
+			b.WriteByte('\n')
+		}
+		for _, cell := range line {
+			b.WriteString(cell)
+		}
+	}
+	return b.String()
+}
+
+// hasControlBytes returns true if input holds a byte RenderTerminal would not copy as is
+func hasControlBytes(input string) bool {
+	for i := 0; i < len(input); i++ {
+		if c := input[i]; (c < 0x20 && c != '\n' && c != '\t') || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// put writes cell at the cursor, padding the line with spaces when the cursor was moved past its end
+func (t *terminal) put(cell string) {
+	line := t.lines[t.row]
+	for len(line) < t.col {
+		line = append(line, " ")
+	}
+	if t.col < len(line) {
+		line[t.col] = cell
+	} else {
+		line = append(line, cell)
+	}
+	t.lines[t.row] = line
+	t.col++
+}
+
+// escape interprets the escape sequence whose introducer follows the ESC at i-1 and returns the index after it
+func (t *terminal) escape(input string, i int) int {
+	if i >= len(input) {
+		return i
+	}
+	switch input[i] {
+	case '[':
+		return t.csi(input, i+1)
+	case ']', 'P', 'X', '^', '_': // OSC, DCS, SOS, PM and APC strings end with BEL or ST
+		for j := i + 1; j < len(input); j++ {
+			if input[j] == 0x07 {
+				return j + 1
+			}
+			if input[j] == 0x1b && j+1 < len(input) && input[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(input)
+	case '7':
+		t.saved = [2]int{t.row, t.col}
+	case '8':
+		t.row, t.col = t.saved[0], t.saved[1]
+		t.grow()
+	case '(', ')', '*', '+', '#', '%': // character set selection takes one more byte
+		return min(i+2, len(input))
+	}
+	return i + 1
+}
+
+// csi interprets the control sequence whose parameters start at i and returns the index after its final byte
+func (t *terminal) csi(input string, i int) int {
+	begin := i
+	for i < len(input) && input[i] >= 0x20 && input[i] <= 0x3f {
+		i++
+	}
+	if i >= len(input) || input[i] < 0x40 || input[i] > 0x7e {
+		return i // not a control sequence, the bytes after ESC [ are printed
+	}
+	params, final := input[begin:i], input[i]
+	n := csiParam(params, 1)
+	switch final {
+	case 'A': // cursor up
+		t.row = max(t.row-n, 0)
+	case 'B': // cursor down
+		t.row = min(t.row+n, len(t.lines)+terminalRowsBelow)
+	case 'C': // cursor forward
+		t.col = min(t.col+n, terminalWidth)
+	case 'D': // cursor back
+		t.col = max(t.col-n, 0)
+	case 'E': // cursor to the beginning of a next line
+		t.row, t.col = min(t.row+n, len(t.lines)+terminalRowsBelow), 0
+	case 'F': // cursor to the beginning of a previous line
+		t.row, t.col = max(t.row-n, 0), 0
+	case 'G': // cursor to a column
+		t.col = min(max(n-1, 0), terminalWidth)
+	case 'K': // erase in line
+		line := t.lines[t.row]
+		switch csiParam(params, 0) {
+		case 0:
+			t.lines[t.row] = line[:min(t.col, len(line))]
+		case 1:
+			for c := 0; c <= t.col && c < len(line); c++ {
+				line[c] = " "
+			}
+		case 2:
+			t.lines[t.row] = nil
+		}
+	case 'J': // erase below the cursor, the rest of the screen is history
+		if csiParam(params, 0) == 0 {
+			t.lines[t.row] = t.lines[t.row][:min(t.col, len(t.lines[t.row]))]
+			t.lines = t.lines[:t.row+1]
+		}
+	}
+	t.grow()
+	return i + 1
+}
+
+// grow adds the lines the cursor was moved down to
+func (t *terminal) grow() {
+	for t.row >= len(t.lines) {
+		t.lines = append(t.lines, nil)
+	}
+}
+
+// csiParam returns the first numeric parameter of a control sequence, or fallback when it is missing or zero
+// where zero means the default
+func csiParam(params string, fallback int) int {
+	first, _, _ := strings.Cut(params, ";")
+	n, err := strconv.Atoi(strings.TrimLeft(first, "?"))
+	if err != nil || (n == 0 && fallback > 0) {
+		return fallback
+	}
+	return n
+}
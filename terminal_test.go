@@ -0,0 +1,60 @@
+package verbose
+
+import "testing"
+
+func TestRenderTerminal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain text", input: "line one\n\tline two\n", want: "line one\n\tline two\n"},
+		{name: "colours", input: "\x1b[1;31merror\x1b[0m: failed\n", want: "error: failed\n"},
+		{name: "carriage return progress", input: "Downloading  10%\rDownloading  55%\rDownloading 100%\ndone\n", want: "Downloading 100%\ndone\n"},
+		{name: "shorter overwrite", input: "Progress: 100/100\rDone\x1b[K\n", want: "Done\n"},
+		{name: "crlf", input: "one\r\ntwo\r\n", want: "one\ntwo\n"},
+		{name: "backspace", input: "spinner |\b/\b-\b\\\b \n", want: "spinner  \n"},
+		{name: "erase line", input: "old text\r\x1b[2Knew\n", want: "new\n"},
+		{name: "column", input: "abcdef\x1b[3GX\n", want: "abXdef\n"},
+		{name: "cursor forward and back", input: "abc\x1b[2CX\x1b[3DY\n", want: "abcY X\n"},
+		{
+			name:  "cursor up redraws lines",
+			input: "#1 layer a 0%\n#2 layer b 0%\n\x1b[2A\r#1 layer a 100%\n#2 layer b 100%\n",
+			want:  "#1 layer a 100%\n#2 layer b 100%\n",
+		},
+		{name: "erase below", input: "keep\nstale one\nstale two\x1b[1A\r\x1b[Jfresh\n", want: "keep\nfresh\n"},
+		{name: "clear screen keeps history", input: "history\n\x1b[2J\x1b[Hnext\n", want: "history\nnext\n"},
+		{name: "window title", input: "\x1b]0;build: step 3\x07compiling\n", want: "compiling\n"},
+		{
+			name:  "hyperlink",
+			input: "see \x1b]8;;https://example.com\x1b\\the docs\x1b]8;;\x1b\\ now\n",
+			want:  "see the docs now\n",
+		},
+		{name: "other controls", input: "bell\x07 and \x00nul\x1b(B\x1b7\x1b8\n", want: "bell and nul\n"},
+		{name: "unicode overwrite", input: "héllo wörld\rHÉ\n", want: "HÉllo wörld\n"},
+		{name: "unterminated sequence", input: "text\x1b[", want: "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if output := RenderTerminal(tt.input); output != tt.want {
+				t.Errorf("RenderTerminal(%q) = %q; want %q", tt.input, output, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveAnsiEscapeCodes(t *testing.T) {
+	input := "\x1b[32mok\x1b[0m \x1b[2K\x1b]0;title\x07done\x1b[?25h"
+	if output := RemoveAnsiEscapeCodes(input); output != "ok done" {
+		t.Errorf("RemoveAnsiEscapeCodes(%q) = %q; want %q", input, output, "ok done")
+	}
+}
+
+func TestRenderTerminalHugeCursorMoves(t *testing.T) {
+	for _, input := range []string{"x\x1b[20000000B", "x\x1b[20000000Ey", "x\x1b[20000000Cy", "x\x1b[20000000Gy", "x\x1b[99999999999999999999Cy"} {
+		output := RenderTerminal(input)
+		if len(output) > terminalWidth+terminalRowsBelow+8 {
+			t.Errorf("RenderTerminal(%q) = %d bytes; want the cursor move capped", input, len(output))
+		}
+	}
+}
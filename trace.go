@@ -1,7 +1,6 @@
 package verbose
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"io"
@@ -9,76 +8,88 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 )
 
 // StackFrame represents a single stack frame with its metadata
 type StackFrame struct {
-	File     string
-	Line     int
-	Function string
-	Content  string
+	File      string
+	Line      int
+	Function  string
+	Content   string
+	Recursion int // the recursive calls of Function that TraceFilter.CollapseRecursion folded into this frame
 }
 
-// getStackFrames returns stack frames starting from skip
-func getStackFrames(skip, maxFrames int) []StackFrame {
-	frames := make([]StackFrame, 0, maxFrames)
+// stackTrace holds the program counters of a captured stack, which are cheap to capture. They are symbolized by
+// frames, which stops once it has the frames a trace logs instead of resolving the whole stack.
+type stackTrace []uintptr
 
-	for i := skip; i < skip+maxFrames; i++ {
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
+// captureStack records the program counters of up to maxFrames callers, skipping skip frames above the caller of
+// captureStack like runtime.Caller(skip) does
+func captureStack(skip, maxFrames int) stackTrace {
+	pcs := make([]uintptr, maxFrames)
+	n := runtime.Callers(skip+2, pcs) // skip runtime.Callers and captureStack
+	return stackTrace(pcs[:n])
+}
 
-		fn := runtime.FuncForPC(pc)
-		if fn == nil {
-			continue
+// frames symbolizes up to maxFrames frames of the stack. Calls that were inlined are reported as frames of their
+// own, attributed to the function that was inlined.
+func (st stackTrace) frames(maxFrames int) []StackFrame {
+	frames := make([]StackFrame, 0, min(len(st), maxFrames))
+	if len(st) == 0 {
+		return frames
+	}
+	callers := runtime.CallersFrames(st)
+	for len(frames) < maxFrames {
+		frame, more := callers.Next()
+		function := frame.Function
+		if len(function) == 0 {
+			function = "?"
 		}
-
-		frame := StackFrame{
-			File:     file,
-			Line:     line,
-			Function: fn.Name(),
+		frames = append(frames, StackFrame{
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: function,
+		})
+		if !more {
+			break
 		}
+	}
+	return frames
+}
+
+// filteredDepth is how many more frames than it logs a trace captures when its TraceFilter drops frames
+const filteredDepth = 4
 
+// getStackFrames returns stack frames starting from skip, dropped, collapsed and trimmed as filter asks when set. It
+// symbolizes them right away, since every trace writes its frames to the log before it returns.
+func getStackFrames(skip, maxFrames int, filter *TraceFilter) []StackFrame {
+	var frames []StackFrame
+	if filter.hides() {
+		depth := maxFrames * filteredDepth
+		frames = filter.apply(captureStack(skip, depth).frames(depth), maxFrames)
+	} else {
+		frames = captureStack(skip, maxFrames).frames(maxFrames)
+	}
+	for i := range frames {
 		// Try to get line content, but don't fail if we can't
-		content, err := getLineContent(file, line)
+		content, err := getLineContent(frames[i].File, frames[i].Line)
 		if err == nil {
-			frame.Content = content
+			frames[i].Content = content
+		}
+		if filter != nil && filter.TrimPaths {
+			frames[i].File = trimPath(frames[i])
 		}
-
-		frames = append(frames, frame)
 	}
-
 	return frames
 }
 
-// getLineContent safely retrieves a specific line from a file
+// getLineContent safely retrieves a specific line from a file, using the line indexes of the source cache
 func getLineContent(filepath string, lineNum int) (string, error) {
 	if lineNum < 1 {
 		return "", fmt.Errorf("invalid line number: %d", lineNum)
 	}
-
-	file, err := os.Open(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	currentLine := 0
-
-	for scanner.Scan() {
-		currentLine++
-		if currentLine == lineNum {
-			return strings.TrimSpace(scanner.Text()), nil
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error scanning file: %w", err)
-	}
-
-	return "", fmt.Errorf("line %d not found in file", lineNum)
+	return sources.line(filepath, lineNum)
 }
 
 // formatStackTrace formats stack frames into a readable string
@@ -88,6 +99,9 @@ func formatStackTrace(frames []StackFrame) string {
 	for _, frame := range frames {
 		// Basic frame info will always be present
 		_, _ = fmt.Fprintf(&b, "\n\tat %s:%d (%s)", frame.File, frame.Line, frame.Function)
+		if frame.Recursion > 0 {
+			_, _ = fmt.Fprintf(&b, " [+%d recursive calls]", frame.Recursion)
+		}
 
 		// Only add the content preview if we have it
 		if frame.Content != "" {
@@ -103,6 +117,7 @@ type Logger struct {
 	*log.Logger
 	file     *os.File
 	maxDepth int // configurable stack depth
+	filter   atomic.Pointer[TraceFilter]
 }
 
 // NewCustomLogger creates a new Logger with the specified configuration
@@ -116,23 +131,27 @@ func NewCustomLogger(w io.Writer, prefix string, flag int, maxDepth int) *Logger
 	}
 }
 
+// TraceReturn logs v with the stack trace of its caller and returns the same text as an error. The frames are
+// symbolized before it returns rather than when the error is read, because the trace is written to the log at once.
 func (l *Logger) TraceReturn(v ...interface{}) error {
 	if v == nil {
 		return nil
 	}
 	msg := fmt.Sprint(v...)
-	frames := getStackFrames(2, l.maxDepth) // Skip Trace() and runtime.Caller
+	frames := getStackFrames(2, l.maxDepth, l.filter.Load()) // Skip Trace() and runtime.Caller
 	trace := formatStackTrace(frames)
 	strace := fmt.Sprintf("%s\nStack Trace:%s\n", msg, trace)
 	return l.Return(strace)
 }
 
+// TracefReturn logs the formatted message with the stack trace of its caller and returns the same text as an error,
+// symbolized right away like TraceReturn
 func (l *Logger) TracefReturn(format string, v ...interface{}) error {
 	if v == nil && format == "" {
 		return nil
 	}
 	msg := fmt.Sprintf(format, v...)
-	frames := getStackFrames(2, l.maxDepth) // Skip Trace() and runtime.Caller
+	frames := getStackFrames(2, l.maxDepth, l.filter.Load()) // Skip Trace() and runtime.Caller
 	trace := formatStackTrace(frames)
 	strace := fmt.Sprintf("%s\nStack Trace:%s\n", msg, trace)
 	return l.Return(strace)
@@ -164,7 +183,7 @@ func (l *Logger) Returnf(format string, v ...interface{}) error {
 
 func (l *Logger) Trace(v ...interface{}) {
 	msg := fmt.Sprint(v...)
-	frames := getStackFrames(2, l.maxDepth) // Skip Trace() and runtime.Caller
+	frames := getStackFrames(2, l.maxDepth, l.filter.Load()) // Skip Trace() and runtime.Caller
 	trace := formatStackTrace(frames)
 	strace := fmt.Sprintf("%s\nStack Trace:%s\n", msg, trace)
 	err := l.Output(2, strace)
@@ -175,7 +194,7 @@ func (l *Logger) Trace(v ...interface{}) {
 
 func (l *Logger) Tracef(format string, v ...interface{}) {
 	msg := fmt.Sprintf(format, v...)
-	frames := getStackFrames(2, l.maxDepth) // Skip Tracef() and runtime.Caller
+	frames := getStackFrames(2, l.maxDepth, l.filter.Load()) // Skip Tracef() and runtime.Caller
 	trace := formatStackTrace(frames)
 	err := l.Output(2, fmt.Sprintf("%s\nStack Trace:%s\n", msg, trace))
 	if err != nil {
@@ -0,0 +1,188 @@
+package verbose
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// TraceFilter customizes the stack frames that Trace, Tracef, TraceReturn and TracefReturn log
+type TraceFilter struct {
+	HideRuntime       bool     // HideRuntime drops the frames of the standard library, like runtime.goexit and testing.tRunner
+	HideWrappers      bool     // HideWrappers drops the frames of this package, like the package level Trace
+	HidePackages      []string // HidePackages drops the frames of these import paths and of the packages below them
+	CollapseRecursion bool     // CollapseRecursion logs consecutive calls of the same function as one frame
+	TrimPaths         bool     // TrimPaths logs files relative to their module, like the module@version/file.go of -trimpath
+}
+
+// SetTraceFilter sets the TraceFilter of the traces the Logger writes
+func (l *Logger) SetTraceFilter(filter TraceFilter) {
+	filter.HidePackages = append([]string(nil), filter.HidePackages...)
+	l.filter.Store(&filter)
+}
+
+// SetTraceFilter sets the TraceFilter of the traces of the package level Trace, Tracef, TraceReturn and TracefReturn
+func SetTraceFilter(filter TraceFilter) error {
+	if vLogr == nil {
+		return errors.New("vLogr not initialized")
+	}
+	vLogr.SetTraceFilter(filter)
+	return nil
+}
+
+// TraceFilter returns the TraceFilter of the traces the Logger writes
+func (l *Logger) TraceFilter() TraceFilter {
+	if filter := l.filter.Load(); filter != nil {
+		return *filter
+	}
+	return TraceFilter{}
+}
+
+// packagePath is the import path of this package, whose frames HideWrappers drops
+var packagePath = funcPackage(runtime.FuncForPC(reflect.ValueOf(funcPackage).Pointer()).Name())
+
+// hides returns true if the filter drops frames, which makes traces capture more frames to fill their depth
+func (f *TraceFilter) hides() bool {
+	return f != nil && (f.HideRuntime || f.HideWrappers || len(f.HidePackages) > 0 || f.CollapseRecursion)
+}
+
+// apply drops and collapses frames as the filter asks, keeping at most maxFrames of them
+func (f *TraceFilter) apply(frames []StackFrame, maxFrames int) []StackFrame {
+	kept := frames[:0]
+	for _, frame := range frames {
+		if len(kept) == maxFrames {
+			break
+		}
+		if f.hidden(frame) {
+			continue
+		}
+		if n := len(kept); f.CollapseRecursion && n > 0 && kept[n-1].Function == frame.Function {
+			kept[n-1].Recursion++
+			continue
+		}
+		kept = append(kept, frame)
+	}
+	return kept
+}
+
+// hidden returns true if the filter drops frame
+func (f *TraceFilter) hidden(frame StackFrame) bool {
+	pkg := funcPackage(frame.Function)
+	if f.HideRuntime && isStandardFrame(frame) {
+		return true
+	}
+	if f.HideWrappers && pkg == packagePath && !strings.HasSuffix(frame.File, "_test.go") {
+		return true
+	}
+	for _, hidden := range f.HidePackages {
+		if pkg == hidden || strings.HasPrefix(pkg, hidden+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// funcPackage returns the import path of the package of the function named function, like net/http for
+// net/http.(*conn).serve or gopkg.in/yaml.v3 for gopkg.in/yaml%2ev3.(*parser).parse
+func funcPackage(function string) string {
+	name := function
+	if i := strings.IndexByte(name, '['); i != -1 {
+		name = name[:i] // type parameters may hold dots and slashes of their own
+	}
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot != -1 {
+		name = name[:slash+1+dot]
+	}
+	return strings.ReplaceAll(name, "%2e", ".") // the linker escapes the dots of the last path element
+}
+
+// isStandardFrame returns true if frame belongs to the standard library, which is decided by the import path of its
+// package rather than by its file, since the file is absolute or relative depending on -trimpath. The packages of the
+// standard library are the ones outside of the main module and of the dependencies whose first element has no dot,
+// like runtime and net/http: a dependency without a dot, like corp/lib, is told apart by its module.
+func isStandardFrame(frame StackFrame) bool {
+	pkg := funcPackage(frame.Function)
+	if pkg == "main" || pkg == "?" || len(pkg) == 0 || len(buildModules().moduleOf(pkg)) > 0 {
+		return false
+	}
+	first, _, _ := strings.Cut(pkg, "/")
+	return !strings.Contains(first, ".")
+}
+
+// modules are the modules of the binary as debug.ReadBuildInfo reports them
+type modules struct {
+	main        string
+	mainPackage string            // the import path of the main package, like example.com/app/cmd/app
+	versions    map[string]string // the version of every dependency by module path, the version of its replacement if any
+}
+
+// moduleOf returns the path of the main or dependency module that holds the package pkg, or "" for the packages of
+// the standard library and of unknown modules
+func (m modules) moduleOf(pkg string) string {
+	if len(m.main) > 0 && (pkg == m.main || strings.HasPrefix(pkg, m.main+"/")) {
+		return m.main
+	}
+	module := ""
+	for candidate := range m.versions {
+		if (pkg == candidate || strings.HasPrefix(pkg, candidate+"/")) && len(candidate) > len(module) {
+			module = candidate
+		}
+	}
+	return module
+}
+
+// buildModules reads the modules of the binary once
+var buildModules = sync.OnceValue(func() modules {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return modules{versions: make(map[string]string)}
+	}
+	return readModules(info)
+})
+
+// readModules returns the main module and the dependency versions of info
+func readModules(info *debug.BuildInfo) modules {
+	m := modules{main: info.Main.Path, mainPackage: info.Path, versions: make(map[string]string)}
+	for _, dep := range info.Deps {
+		version := dep.Version
+		if dep.Replace != nil {
+			version = dep.Replace.Version // frames keep the import paths of the module that was replaced
+		}
+		m.versions[dep.Path] = version
+	}
+	return m
+}
+
+// trimPath returns the file of frame relative to the root of its module, prefixed with the module path and version
+// for dependencies and with the package path for the standard library. The package main is found in the main module
+// by the import path of the main package.
+func trimPath(frame StackFrame) string {
+	pkg := funcPackage(frame.Function)
+	if len(pkg) == 0 || pkg == "?" || len(frame.File) == 0 {
+		return frame.File
+	}
+	file := path.Base(strings.ReplaceAll(frame.File, "\\", "/"))
+	modules := buildModules()
+	if pkg == "main" {
+		if len(modules.main) == 0 || modules.moduleOf(modules.mainPackage) != modules.main {
+			return file
+		}
+		pkg = modules.mainPackage
+	}
+	module := modules.moduleOf(pkg)
+	if len(module) > 0 && module == modules.main {
+		return path.Join(strings.TrimPrefix(strings.TrimPrefix(pkg, modules.main), "/"), file)
+	}
+	if len(module) == 0 {
+		return path.Join(pkg, file)
+	}
+	dir := strings.TrimPrefix(pkg, module)
+	if version := modules.versions[module]; len(version) > 0 {
+		module += "@" + version // modules replaced by a directory have no version
+	}
+	return path.Join(module, dir, file)
+}
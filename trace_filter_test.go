@@ -0,0 +1,200 @@
+package verbose
+
+import (
+	"bytes"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestFuncPackage(t *testing.T) {
+	tests := map[string]string{
+		"runtime.goexit":         "runtime",
+		"main.main":              "main",
+		"net/http.(*conn).serve": "net/http",
+		"github.com/andreimerlescu/verbose.Trace":     "github.com/andreimerlescu/verbose",
+		"github.com/andreimerlescu/verbose.Map[...]":  "github.com/andreimerlescu/verbose",
+		"gopkg.in/yaml%2ev3.(*parser).parse":          "gopkg.in/yaml.v3",
+		"example.com/x.Do[go.shape.struct { A int }]": "example.com/x",
+		"example.com/x.(*T[go.shape.string]).M.func1": "example.com/x",
+		"?": "?",
+	}
+	for function, want := range tests {
+		if got := funcPackage(function); got != want {
+			t.Errorf("funcPackage(%q) = %q; want %q", function, got, want)
+		}
+	}
+}
+
+func TestIsStandardFrame(t *testing.T) {
+	defer func(read func() modules) { buildModules = read }(buildModules)
+	buildModules = func() modules {
+		return modules{main: "corp/app", versions: map[string]string{"corp/lib": "v1.0.0", "gopkg.in/yaml.v3": "v3.0.1"}}
+	}
+	tests := []struct {
+		frame StackFrame
+		want  bool
+	}{
+		{StackFrame{File: "/usr/local/go/src/runtime/proc.go", Function: "runtime.main"}, true},
+		{StackFrame{File: "/opt/go/src/net/http/server.go", Function: "net/http.(*conn).serve"}, true},
+		{StackFrame{File: "runtime/proc.go", Function: "runtime.main"}, true},
+		{StackFrame{File: "net/http/server.go", Function: "net/http.(*conn).serve"}, true},
+		{StackFrame{File: "/home/ci/app/main.go", Function: "main.main"}, false},
+		{StackFrame{File: "/home/ci/app/internal/db/db.go", Function: "corp/app/internal/db.Open"}, false},
+		{StackFrame{File: "corp/app/internal/db/db.go", Function: "corp/app/internal/db.Open"}, false},
+		{StackFrame{File: "/home/ci/go/pkg/mod/corp/lib@v1.0.0/lib.go", Function: "corp/lib.Do"}, false},
+		{StackFrame{File: "corp/lib@v1.0.0/lib.go", Function: "corp/lib.Do"}, false},
+		{StackFrame{File: "/home/ci/go/pkg/mod/gopkg.in/yaml.v3@v3.0.1/parserc.go", Function: "gopkg.in/yaml%2ev3.(*parser).parse"}, false},
+		{StackFrame{File: "/home/ci/verbose/trace.go", Function: packagePath + ".Trace"}, false},
+		{StackFrame{File: "", Function: "?"}, false},
+	}
+	for _, test := range tests {
+		if got := isStandardFrame(test.frame); got != test.want {
+			t.Errorf("isStandardFrame(%s) = %v; want %v", test.frame.File, got, test.want)
+		}
+	}
+}
+
+func TestReadModules(t *testing.T) {
+	info := &debug.BuildInfo{
+		Path: "example.com/app/cmd/app",
+		Main: debug.Module{Path: "example.com/app"},
+		Deps: []*debug.Module{
+			{Path: "corp/lib", Version: "v1.0.0", Replace: &debug.Module{Path: "corp/fork", Version: "v1.0.1"}},
+			{Path: "example.com/local", Version: "v0.1.0", Replace: &debug.Module{Path: "../local"}},
+			{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+		},
+	}
+	m := readModules(info)
+	want := map[string]string{"corp/lib": "v1.0.1", "example.com/local": "", "gopkg.in/yaml.v3": "v3.0.1"}
+	if m.main != "example.com/app" || m.mainPackage != "example.com/app/cmd/app" || len(m.versions) != len(want) {
+		t.Fatalf("readModules() = %+v; want %v", m, want)
+	}
+	for module, version := range want {
+		if got, ok := m.versions[module]; !ok || got != version {
+			t.Errorf("readModules() version of %s = %q; want %q", module, got, version)
+		}
+	}
+}
+
+func TestTraceFilterApply(t *testing.T) {
+	frames := func() []StackFrame {
+		return []StackFrame{
+			{File: "/src/verbose/verbose.go", Function: packagePath + ".Trace"},
+			{File: "/src/app/walk.go", Function: "example.com/app.walk"},
+			{File: "/src/app/walk.go", Function: "example.com/app.walk"},
+			{File: "/src/app/walk.go", Function: "example.com/app.walk"},
+			{File: "/src/lib/mux.go", Function: "example.com/lib/mux.(*Router).ServeHTTP"},
+			{File: "/src/app/main.go", Function: "main.main"},
+			{File: "/usr/local/go/src/runtime/proc.go", Function: "runtime.main"},
+			{File: "/usr/local/go/src/runtime/asm_amd64.s", Function: "runtime.goexit"},
+		}
+	}
+	functions := func(frames []StackFrame) string {
+		var names []string
+		for _, frame := range frames {
+			names = append(names, frame.Function)
+		}
+		return strings.Join(names, ",")
+	}
+
+	filter := &TraceFilter{HideRuntime: true, HideWrappers: true, HidePackages: []string{"example.com/lib"}, CollapseRecursion: true}
+	got := filter.apply(frames(), 10)
+	if want := "example.com/app.walk,main.main"; functions(got) != want {
+		t.Fatalf("apply() = %s; want %s", functions(got), want)
+	}
+	if got[0].Recursion != 2 || got[1].Recursion != 0 {
+		t.Errorf("apply() recursion = %d, %d; want 2, 0", got[0].Recursion, got[1].Recursion)
+	}
+
+	if got := (&TraceFilter{HidePackages: []string{"example.com/ap"}}).apply(frames(), 10); len(got) != 8 {
+		t.Errorf("apply() hid %s; want only whole packages hidden", functions(got))
+	}
+	if got := (&TraceFilter{HideRuntime: true}).apply(frames(), 3); len(got) != 3 || got[0].Function != packagePath+".Trace" {
+		t.Errorf("apply() = %s; want the first 3 frames", functions(got))
+	}
+	if (&TraceFilter{TrimPaths: true}).hides() || (*TraceFilter)(nil).hides() {
+		t.Errorf("hides() = true; want false when no frame is dropped")
+	}
+}
+
+func TestTrimPath(t *testing.T) {
+	tests := []struct {
+		frame StackFrame
+		want  string
+	}{
+		{StackFrame{File: "/home/ci/verbose/trace.go", Function: packagePath + ".getStackFrames"}, "trace.go"},
+		{StackFrame{File: "/home/ci/verbose/cmd/verbose/main.go", Function: packagePath + "/cmd/verbose.run"}, "cmd/verbose/main.go"},
+		{StackFrame{File: "/usr/local/go/src/runtime/proc.go", Function: "runtime.main"}, "runtime/proc.go"},
+		{StackFrame{File: "/usr/local/go/src/net/http/server.go", Function: "net/http.(*conn).serve"}, "net/http/server.go"},
+		{StackFrame{File: "", Function: "?"}, ""},
+	}
+	for _, test := range tests {
+		if got := trimPath(test.frame); got != test.want {
+			t.Errorf("trimPath(%s) = %q; want %q", test.frame.Function, got, test.want)
+		}
+	}
+
+	defer func(read func() modules) { buildModules = read }(buildModules)
+	buildModules = func() modules {
+		return modules{main: "corp/app", mainPackage: "corp/app/cmd/app", versions: map[string]string{"corp/lib": "v1.0.0"}}
+	}
+	tests = []struct {
+		frame StackFrame
+		want  string
+	}{
+		{StackFrame{File: "/home/ci/app/cmd/app/main.go", Function: "main.main"}, "cmd/app/main.go"},
+		{StackFrame{File: "corp/app/cmd/app/main.go", Function: "main.main"}, "cmd/app/main.go"},
+		{StackFrame{File: "/home/ci/go/pkg/mod/corp/lib@v1.0.0/sub/lib.go", Function: "corp/lib/sub.Do"}, "corp/lib@v1.0.0/sub/lib.go"},
+	}
+	for _, test := range tests {
+		if got := trimPath(test.frame); got != test.want {
+			t.Errorf("trimPath(%s) = %q; want %q", test.frame.Function, got, test.want)
+		}
+	}
+
+	buildModules = func() modules { return modules{versions: map[string]string{}} }
+	if got := trimPath(StackFrame{File: "/home/ci/app/main.go", Function: "main.main"}); got != "main.go" {
+		t.Errorf("trimPath(main.main) = %q; want main.go without a main module", got)
+	}
+}
+
+// traceRecursively calls itself depth times before it traces with l
+func traceRecursively(l *Logger, depth int) {
+	if depth > 0 {
+		traceRecursively(l, depth-1)
+		return
+	}
+	l.Trace("deep")
+}
+
+func TestLoggerTraceFilter(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewCustomLogger(&buf, "", 0, 10)
+	traceRecursively(l, 3)
+	if out := buf.String(); !strings.Contains(out, "testing.tRunner") || strings.Count(out, ".traceRecursively") != 4 {
+		t.Fatalf("Trace() without a filter = %s", out)
+	}
+
+	buf.Reset()
+	l.SetTraceFilter(TraceFilter{HideRuntime: true, CollapseRecursion: true, TrimPaths: true})
+	traceRecursively(l, 3)
+	out := buf.String()
+	for _, hidden := range []string{"testing.tRunner", "runtime.goexit", "/trace_filter_test.go"} {
+		if strings.Contains(out, hidden) {
+			t.Errorf("Trace() = %s; want no %s", out, hidden)
+		}
+	}
+	for _, want := range []string{"at trace_filter_test.go:", "(" + packagePath + ".traceRecursively) [+3 recursive calls]", ".TestLoggerTraceFilter)", "→ l.Trace(\"deep\")"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Trace() = %s; want %s", out, want)
+		}
+	}
+	if strings.Count(out, ".traceRecursively") != 1 {
+		t.Errorf("Trace() = %s; want the recursion collapsed into one frame", out)
+	}
+
+	if filter := l.TraceFilter(); !filter.HideRuntime || filter.HideWrappers {
+		t.Errorf("TraceFilter() = %+v", filter)
+	}
+}
@@ -0,0 +1,71 @@
+package verbose
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// legacyStackFrames is the runtime.Caller and runtime.FuncForPC capture that captureStack replaced, kept to compare
+// their cost, without reading line contents
+func legacyStackFrames(skip, maxFrames int) []StackFrame {
+	frames := make([]StackFrame, 0, maxFrames)
+	for i := skip; i < skip+maxFrames; i++ {
+		pc, file, line, ok := runtime.Caller(i)
+		if !ok {
+			break
+		}
+		fn := runtime.FuncForPC(pc)
+		if fn == nil {
+			continue
+		}
+		frames = append(frames, StackFrame{File: file, Line: line, Function: fn.Name()})
+	}
+	return frames
+}
+
+// traceInlined is small enough to be inlined into its callers
+func traceInlined() []StackFrame {
+	return getStackFrames(1, 2, nil)
+}
+
+func TestGetStackFramesInlined(t *testing.T) {
+	frames := traceInlined()
+	if len(frames) != 2 {
+		t.Fatalf("getStackFrames() = %+v; want 2 frames", frames)
+	}
+	if !strings.HasSuffix(frames[0].Function, ".traceInlined") {
+		t.Errorf("frame 0 = %s; want traceInlined", frames[0].Function)
+	}
+	if !strings.HasSuffix(frames[1].Function, ".TestGetStackFramesInlined") {
+		t.Errorf("frame 1 = %s; want TestGetStackFramesInlined, not the function inlined into it", frames[1].Function)
+	}
+	if !strings.HasSuffix(frames[0].File, "trace_test.go") || frames[0].Content != "return getStackFrames(1, 2, nil)" {
+		t.Errorf("frame 0 = %s:%d %q", frames[0].File, frames[0].Line, frames[0].Content)
+	}
+}
+
+func TestCaptureStackSkip(t *testing.T) {
+	legacy, frames := legacyStackFrames(1, 1), captureStack(0, 1).frames(1)
+	if len(frames) != 1 || frames[0].Function != legacy[0].Function {
+		t.Errorf("captureStack(0) = %+v; want the caller of captureStack like runtime.Caller(0): %+v", frames, legacy)
+	}
+}
+
+func BenchmarkStackCapture(b *testing.B) {
+	b.Run("callers", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = captureStack(0, 10).frames(10)
+		}
+	})
+	b.Run("callers-capture-only", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = captureStack(0, 10)
+		}
+	})
+	b.Run("legacy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = legacyStackFrames(0, 10)
+		}
+	})
+}
@@ -149,17 +149,43 @@ func NewLogger(opts Options) error {
 	if len(opts.Dir) > 0 {
 		Dir = strings.Clone(opts.Dir)
 	}
-	dirInfo, infoErr := os.Stat(Dir)
+	logFile, openErr := openLogFile(Dir, opts)
+	if openErr != nil {
+		return openErr
+	}
+	vLogr = NewCustomLogger(logFile, "[VERBOSE] ", log.Ldate|log.Ltime|log.Lshortfile, 10)
+	if vLogr == nil {
+		return errors.New("verbose vLogr is still nil after being defined")
+	}
+	return nil
+}
+
+// OpenLogFile opens the log file NewLogger would write to for opts, creating its directory, without replacing the
+// logger of the package. The caller closes the file.
+func OpenLogFile(opts Options) (*os.File, error) {
+	dir := opts.Dir
+	if len(dir) == 0 {
+		dir = Dir
+	}
+	if len(dir) == 0 {
+		dir = filepath.Join(".", "logs")
+	}
+	return openLogFile(dir, opts)
+}
+
+// openLogFile creates dir with the DirMode of opts and opens the log file named by opts inside it
+func openLogFile(dir string, opts Options) (*os.File, error) {
+	dirInfo, infoErr := os.Stat(dir)
 	if infoErr == nil && !dirInfo.IsDir() {
-		return Errorf(log.Default(), "%v is not a directory", Dir)
+		return nil, Errorf(log.Default(), "%v is not a directory", dir)
 	}
 	var dirPerms os.FileMode = 0700
 	if opts.DirMode != 0 {
 		dirPerms = opts.DirMode
 	}
-	mkdirErr := os.MkdirAll(Dir, dirPerms)
+	mkdirErr := os.MkdirAll(dir, dirPerms)
 	if mkdirErr != nil {
-		return mkdirErr
+		return nil, mkdirErr
 	}
 	var logFlags int
 	if opts.Truncate {
@@ -173,17 +199,13 @@ func NewLogger(opts Options) error {
 	}
 	var filename string
 	if len(opts.Name) > 0 {
-		filename = filepath.Join(Dir, fmt.Sprintf("%s.log", opts.Name))
+		filename = filepath.Join(dir, fmt.Sprintf("%s.log", opts.Name))
 	} else {
-		filename = filepath.Join(Dir, "verbose.log")
+		filename = filepath.Join(dir, "verbose.log")
 	}
 	logFile, openErr := os.OpenFile(filename, logFlags, filePerms)
 	if openErr != nil {
-		return fmt.Errorf("error opening file: %v", openErr)
+		return nil, fmt.Errorf("error opening file: %v", openErr)
 	}
-	vLogr = NewCustomLogger(logFile, "[VERBOSE] ", log.Ldate|log.Ltime|log.Lshortfile, 10)
-	if vLogr == nil {
-		return errors.New("verbose vLogr is still nil after being defined")
-	}
-	return nil
+	return logFile, nil
 }
@@ -0,0 +1,288 @@
+package verbose
+
+import (
+	"regexp"
+	"strings"
+)
+
+// yamlRule labels the values the structural YAML pass cleans
+const yamlRule = "yaml"
+
+// MinYAMLLines is the number of "key: value" lines a run of flat YAML lines needs before the structural YAML pass
+// runs on it. Runs with nested keys, sequence items or block scalars are always read as YAML.
+var MinYAMLLines = 3
+
+var (
+	// yamlKeyRegex matches YAML mapping lines like "  password: abc # comment" and "- name: web"
+	yamlKeyRegex = regexp.MustCompile(`^( *)(- +)?("[^"]*"|'[^']*'|[^\s#:"'\-][^:#]*?|-[^\s:#][^:#]*?) *:(?: +(.*?))? *$`)
+	// yamlItemRegex matches YAML sequence items like "  - FOO_TOKEN=abc"
+	yamlItemRegex = regexp.MustCompile(`^( *)- +(.*?) *$`)
+	// yamlSecretKindRegex matches the kind of a Kubernetes Secret manifest
+	yamlSecretKindRegex = regexp.MustCompile(`(?m)^kind: *["']?Secret["']? *$`)
+	// yamlBlockScalarRegex matches the indicators of literal and folded block scalars like | and >-
+	yamlBlockScalarRegex = regexp.MustCompile(`^[|>][+-]?[0-9]?$`)
+)
+
+// yamlKey is a mapping key whose nested lines are still being read
+type yamlKey struct {
+	indent    int
+	name      string
+	sensitive bool // every value nested under the key is secret
+}
+
+// yamlEnvItem is a Kubernetes env entry like "- name: DB_PASSWORD" whose value is secret when its name is
+type yamlEnvItem struct {
+	indent int            // the indent of the keys of the entry, -1 outside of an entry
+	secret bool           // the name of the entry is a secret variable
+	values []cleanerMatch // the values read before the name
+}
+
+// isSensitiveYAMLKey classifies a key with IsSecretEnv when it is an environment variable name like DB_PASSWORD,
+// and with IsSensitiveKey otherwise
+func isSensitiveYAMLKey(key string) bool {
+	if strings.ToUpper(key) == key && strings.ContainsAny(key, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+		return isSecretKey(key)
+	}
+	return IsSensitiveKey(key)
+}
+
+// findYAML returns the scalar values of YAML documents in input that are secret along with the spans of the lines
+// that were understood as YAML and of the values left alone. Secret values are the ones under SensitiveKeys at any
+// depth, every value under the data and stringData of a Kubernetes Secret and the secret variables of docker-compose
+// environment blocks and Kubernetes env entries. Every run of YAML lines is read on its own, so that a log line
+// before a manifest does not hide it. Runs with a line that is not YAML or a key repeated in a mapping, like a log
+// whose lines start with INFO:, are skipped so that the substring rules clean them.
+func findYAML(input string) (matches, regions, values []cleanerMatch) {
+	if !strings.Contains(input, ":") {
+		return
+	}
+	offset := 0
+	for _, document := range splitYAMLRuns(strings.SplitAfter(input, "\n")) {
+		if !isYAMLDocument(document) || !isStructuredYAML(document) {
+			for _, raw := range document {
+				offset += len(raw)
+			}
+			continue
+		}
+		secretKind := yamlSecretKindRegex.MatchString(strings.Join(document, ""))
+		var stack []yamlKey
+		block, blockIndent, blockSensitive, blockValue := false, 0, false, -1
+		env := yamlEnvItem{indent: -1}
+		for _, raw := range document {
+			line := strings.TrimRight(raw, "\r\n")
+			start := offset
+			offset += len(raw)
+			trimmed := strings.TrimLeft(line, " ")
+			indent := len(line) - len(trimmed)
+			if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if block && indent > blockIndent {
+				held := &values
+				if blockSensitive {
+					held = &matches
+				}
+				if blockValue == -1 {
+					blockValue = len(*held)
+					*held = append(*held, cleanerMatch{start: start + indent, end: start + len(line), rule: yamlRule})
+				} else {
+					(*held)[blockValue].end = start + len(line) // the lines of a block scalar are one value
+				}
+				regions = append(regions, cleanerMatch{start: start, end: start + len(line), rule: yamlRule})
+				continue
+			}
+			block, blockValue = false, -1
+			item := strings.HasPrefix(trimmed, "- ")
+			for len(stack) > 0 && (stack[len(stack)-1].indent > indent || stack[len(stack)-1].indent == indent && !item) {
+				stack = stack[:len(stack)-1] // sequence items may sit at the indent of the key that holds them
+			}
+			var parent yamlKey
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			if m := yamlKeyRegex.FindStringSubmatchIndex(line); m != nil {
+				keyIndent := m[3] - m[2]
+				if m[4] != -1 {
+					keyIndent += m[5] - m[4] // the key of a mapping inside a sequence item
+					for len(stack) > 0 && stack[len(stack)-1].indent >= keyIndent {
+						stack = stack[:len(stack)-1]
+					}
+				}
+				name := strings.Trim(line[m[6]:m[7]], `"'`)
+				sensitive := parent.sensitive || isSensitiveYAMLKey(name) ||
+					(secretKind && keyIndent == 0 && (name == "data" || name == "stringData"))
+				regions = append(regions, cleanerMatch{start: start, end: start + len(line), rule: yamlRule})
+				if m[4] != -1 && parent.name == "env" {
+					env = yamlEnvItem{indent: keyIndent}
+				} else if keyIndent < env.indent {
+					env = yamlEnvItem{indent: -1}
+				}
+				if m[8] == -1 || m[9] == m[8] {
+					stack = append(stack, yamlKey{indent: keyIndent, name: name, sensitive: sensitive})
+					continue
+				}
+				if yamlBlockScalarRegex.MatchString(line[m[8]:m[9]]) {
+					block, blockIndent, blockSensitive = true, keyIndent, sensitive
+					continue
+				}
+				if keyIndent == env.indent && name == "name" {
+					if value := appendYAMLScalar(nil, line, 0, m[8], m[9]); len(value) > 0 &&
+						isSensitiveYAMLKey(line[value[0].start:value[0].end]) {
+						env.secret = true
+						matches = append(matches, env.values...)
+					}
+				} else if keyIndent == env.indent && name == "value" && !sensitive {
+					if env.secret {
+						sensitive = true
+					} else {
+						env.values = appendYAMLScalar(env.values, line, start, m[8], m[9])
+					}
+				}
+				if sensitive {
+					matches = appendYAMLScalar(matches, line, start, m[8], m[9])
+				} else {
+					values = appendYAMLScalar(values, line, start, m[8], m[9])
+				}
+				continue
+			}
+			if m := yamlItemRegex.FindStringSubmatchIndex(line); m != nil && m[5] > m[4] {
+				regions = append(regions, cleanerMatch{start: start, end: start + len(line), rule: yamlRule})
+				if parent.sensitive {
+					matches = appendYAMLScalar(matches, line, start, m[4], m[5])
+				} else if name, value, found := strings.Cut(line[m[4]:m[5]], "="); found && len(value) > 0 &&
+					(parent.name == "environment" || parent.name == "env") && isSensitiveYAMLKey(name) {
+					matches = appendYAMLScalar(matches, line, start, m[4]+len(name)+1, m[5])
+				} else {
+					values = appendYAMLScalar(values, line, start, m[4], m[5])
+				}
+			}
+		}
+	}
+	return
+}
+
+// isYAMLDocument returns true if every line of document is a mapping key, a sequence item, a line of a block scalar
+// or the continuation of a value, and no key is repeated within a mapping
+func isYAMLDocument(document []string) bool {
+	seen := make(map[int]map[string]bool) // the keys of the open mappings by indent
+	block, keyIndent := false, -1
+	for _, raw := range document {
+		line := strings.TrimRight(raw, "\r\n")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		if keyIndent != -1 && indent > keyIndent && (block || yamlKeyRegex.FindStringSubmatchIndex(line) == nil) {
+			continue // a line of a block scalar or of a plain scalar that goes on under its key
+		}
+		block = false
+		m := yamlKeyRegex.FindStringSubmatchIndex(line)
+		if m == nil {
+			if yamlItemRegex.MatchString(line) {
+				for i := range seen {
+					if i > indent {
+						delete(seen, i)
+					}
+				}
+				keyIndent = indent
+				continue
+			}
+			return false
+		}
+		keyIndent = m[3] - m[2]
+		if m[4] != -1 {
+			keyIndent += m[5] - m[4]
+			delete(seen, keyIndent) // a sequence item starts a new mapping
+		}
+		for i := range seen {
+			if i > keyIndent {
+				delete(seen, i)
+			}
+		}
+		name := strings.Trim(line[m[6]:m[7]], `"'`)
+		if seen[keyIndent] == nil {
+			seen[keyIndent] = make(map[string]bool)
+		}
+		if seen[keyIndent][name] {
+			return false
+		}
+		seen[keyIndent][name] = true
+		block = m[8] != -1 && yamlBlockScalarRegex.MatchString(line[m[8]:m[9]])
+	}
+	return true
+}
+
+// splitYAMLRuns splits lines into the runs of YAML lines separated by --- lines and by unindented lines that are
+// neither keys nor sequence items, like a log line or a shell prompt. Every line that is not YAML is a run of its own.
+func splitYAMLRuns(lines []string) [][]string {
+	var runs [][]string
+	begin := 0
+	for i, raw := range lines {
+		line := strings.TrimRight(raw, "\r\n")
+		switch {
+		case strings.TrimRight(line, " ") == "---":
+			runs = append(runs, lines[begin:i+1])
+			begin = i + 1
+		case len(line) > 0 && line[0] != ' ' && line[0] != '#' && line != "..." &&
+			!yamlKeyRegex.MatchString(line) && !yamlItemRegex.MatchString(line):
+			if i > begin {
+				runs = append(runs, lines[begin:i])
+			}
+			runs = append(runs, lines[i:i+1])
+			begin = i + 1
+		}
+	}
+	return append(runs, lines[begin:])
+}
+
+// isStructuredYAML returns true if document has nested lines, sequence items or block scalars, or at least
+// MinYAMLLines keys: a couple of flat "key: value" lines are left to the substring rules
+func isStructuredYAML(document []string) bool {
+	keyLines := 0
+	for _, raw := range document {
+		line := strings.TrimRight(raw, "\r\n")
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if line[0] == ' ' || strings.HasPrefix(line, "- ") {
+			return true
+		}
+		if yamlKeyRegex.MatchString(line) {
+			keyLines++
+		}
+	}
+	return keyLines >= MinYAMLLines
+}
+
+// appendYAMLScalar appends the scalar of line between from and to, without its quotes or trailing comment
+func appendYAMLScalar(matches []cleanerMatch, line string, offset, from, to int) []cleanerMatch {
+	value := line[from:to]
+	if quote := value[0]; quote == '"' || quote == '\'' {
+		closing := 1
+		for closing < len(value) && (value[closing] != quote || value[closing-1] == '\\' && quote == '"') {
+			closing++
+		}
+		from, to = from+1, from+closing
+	} else {
+		if i := strings.Index(value, " #"); i != -1 {
+			to = from + len(strings.TrimRight(value[:i], " "))
+		}
+		if isBoolOrNull(line[from:to]) {
+			return matches
+		}
+	}
+	if to > from {
+		matches = append(matches, cleanerMatch{start: offset + from, end: offset + to, rule: yamlRule})
+	}
+	return matches
+}
+
+// isBoolOrNull returns true if value is an unquoted boolean or null, which never holds a secret, like private: true
+func isBoolOrNull(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "false", "null", "~":
+		return true
+	}
+	return false
+}